@@ -0,0 +1,413 @@
+package otelanthropic
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	sdk "github.com/traceloop/go-openllmetry/traceloop-sdk"
+	"github.com/traceloop/go-openllmetry/traceloop-sdk/llmhttp"
+)
+
+type messagesRequest struct {
+	Model         string          `json:"model"`
+	System        json.RawMessage `json:"system"`
+	Messages      []message       `json:"messages"`
+	Tools         []tool          `json:"tools"`
+	Temperature   *float32        `json:"temperature"`
+	TopP          *float32        `json:"top_p"`
+	StopSequences []string        `json:"stop_sequences"`
+	MaxTokens     int             `json:"max_tokens"`
+	ToolChoice    toolChoice      `json:"tool_choice"`
+	Metadata      metadata        `json:"metadata"`
+}
+
+type metadata struct {
+	UserID string `json:"user_id"`
+}
+
+// toolChoice is "auto", "any" or "none", or names the tool to call when its
+// type is "tool".
+type toolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+type message struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+type tool struct {
+	Type        string      `json:"type"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	InputSchema interface{} `json:"input_schema"`
+}
+
+// contentBlock covers the text, image, document, tool_use and tool_result
+// blocks.
+type contentBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text"`
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+	Content   json.RawMessage `json:"content"`
+	Source    *blockSource    `json:"source"`
+	Title     string          `json:"title"`
+	ToolUseID string          `json:"tool_use_id"`
+}
+
+// blockSource is the base64, url, file or text source of an image or a
+// document block.
+type blockSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type"`
+	Data      string `json:"data"`
+	URL       string `json:"url"`
+	FileID    string `json:"file_id"`
+}
+
+type usage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+type messagesResponse struct {
+	ID         string         `json:"id"`
+	Model      string         `json:"model"`
+	Role       string         `json:"role"`
+	Content    []contentBlock `json:"content"`
+	StopReason string         `json:"stop_reason"`
+	Usage      usage          `json:"usage"`
+}
+
+// messagesEndpoint parses the bodies of the Messages API.
+type messagesEndpoint struct{}
+
+func (messagesEndpoint) Prompt(body []byte) (sdk.Prompt, error) {
+	var request messagesRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		return sdk.Prompt{}, err
+	}
+
+	var messages []sdk.Message
+	if system := blocksText(parseBlocks(request.System)); system != "" {
+		messages = append(messages, sdk.Message{
+			Index:   0,
+			Role:    "system",
+			Content: system,
+		})
+	}
+
+	for _, m := range request.Messages {
+		blocks := parseBlocks(m.Content)
+		messages = append(messages, sdk.Message{
+			Index:        len(messages),
+			Role:         m.Role,
+			Content:      blocksText(blocks),
+			ContentParts: blocksContentParts(blocks),
+			ToolCalls:    blocksToolCalls(blocks),
+			ToolCallID:   blocksToolCallID(blocks),
+		})
+	}
+
+	var tools []sdk.Tool
+	for _, t := range request.Tools {
+		toolType := t.Type
+		if toolType == "" {
+			toolType = "function"
+		}
+		tools = append(tools, sdk.Tool{
+			Type: toolType,
+			Function: sdk.ToolFunction{
+				Name:        t.Name,
+				Description: t.Description,
+				Parameters:  t.InputSchema,
+			},
+		})
+	}
+
+	return sdk.Prompt{
+		Vendor:      vendor,
+		Mode:        "chat",
+		Model:       request.Model,
+		Temperature: request.Temperature,
+		TopP:        request.TopP,
+		Stop:        request.StopSequences,
+		MaxTokens:   request.MaxTokens,
+		ToolChoice:  toolChoiceName(request.ToolChoice),
+		User:        request.Metadata.UserID,
+		Messages:    messages,
+		Tools:       tools,
+	}, nil
+}
+
+func toolChoiceName(choice toolChoice) string {
+	if choice.Type == "tool" {
+		return choice.Name
+	}
+
+	return choice.Type
+}
+
+func (messagesEndpoint) Completion(body []byte) (sdk.Completion, sdk.Usage, error) {
+	var response messagesResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return sdk.Completion{}, sdk.Usage{}, err
+	}
+
+	return response.toCompletion(), response.Usage.toUsage(), nil
+}
+
+func (messagesEndpoint) StreamDecoder() llmhttp.StreamDecoder {
+	return &messageDecoder{toolCalls: make(map[int]*toolUseBlock)}
+}
+
+func (response messagesResponse) toCompletion() sdk.Completion {
+	role := response.Role
+	if role == "" {
+		role = "assistant"
+	}
+
+	return sdk.Completion{
+		ID:    response.ID,
+		Model: response.Model,
+		Messages: []sdk.Message{
+			{
+				Index:        0,
+				Role:         role,
+				Content:      blocksText(response.Content),
+				ToolCalls:    blocksToolCalls(response.Content),
+				FinishReason: response.StopReason,
+			},
+		},
+	}
+}
+
+func (u usage) toUsage() sdk.Usage {
+	// input_tokens excludes the tokens read from or written to the prompt
+	// cache, so the prompt is the sum of all three.
+	promptTokens := u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+
+	return sdk.Usage{
+		TotalTokens:              promptTokens + u.OutputTokens,
+		CompletionTokens:         u.OutputTokens,
+		PromptTokens:             promptTokens,
+		CacheCreationInputTokens: u.CacheCreationInputTokens,
+		CacheReadInputTokens:     u.CacheReadInputTokens,
+	}
+}
+
+// parseBlocks decodes content that is either a plain string or an array of
+// content blocks.
+func parseBlocks(raw json.RawMessage) []contentBlock {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return []contentBlock{{Type: "text", Text: text}}
+	}
+
+	var blocks []contentBlock
+	json.Unmarshal(raw, &blocks)
+	return blocks
+}
+
+func blocksText(blocks []contentBlock) string {
+	var texts []string
+	for _, block := range blocks {
+		switch block.Type {
+		case "text":
+			texts = append(texts, block.Text)
+		case "tool_result":
+			texts = append(texts, blocksText(parseBlocks(block.Content)))
+		}
+	}
+
+	return strings.Join(texts, "\n")
+}
+
+// blocksContentParts converts the blocks of messages carrying images or
+// documents. Text-only content has no parts, as it is recorded as the
+// message's content.
+func blocksContentParts(blocks []contentBlock) []sdk.ContentPart {
+	var parts []sdk.ContentPart
+	hasBinary := false
+	for _, block := range blocks {
+		switch block.Type {
+		case "text":
+			parts = append(parts, sdk.ContentPart{Type: sdk.ContentPartText, Text: block.Text})
+		case "image", "document":
+			hasBinary = true
+			parts = append(parts, block.toContentPart())
+		}
+	}
+	if !hasBinary {
+		return nil
+	}
+
+	return parts
+}
+
+func (block contentBlock) toContentPart() sdk.ContentPart {
+	part := sdk.ContentPart{Type: sdk.ContentPartImage}
+	if block.Type == "document" {
+		part = sdk.ContentPart{Type: sdk.ContentPartFile, Filename: block.Title}
+	}
+	if block.Source == nil {
+		return part
+	}
+
+	part.MimeType = block.Source.MediaType
+	part.URL = block.Source.URL
+	part.FileID = block.Source.FileID
+	switch block.Source.Type {
+	case "base64":
+		part.Data, _ = base64.StdEncoding.DecodeString(block.Source.Data)
+	case "text":
+		part.Data = []byte(block.Source.Data)
+	}
+
+	return part
+}
+
+// blocksToolCallID returns the tool call answered by the first tool_result
+// block of a message.
+func blocksToolCallID(blocks []contentBlock) string {
+	for _, block := range blocks {
+		if block.Type == "tool_result" {
+			return block.ToolUseID
+		}
+	}
+
+	return ""
+}
+
+func blocksToolCalls(blocks []contentBlock) []sdk.ToolCall {
+	var toolCalls []sdk.ToolCall
+	for _, block := range blocks {
+		if block.Type != "tool_use" {
+			continue
+		}
+		toolCalls = append(toolCalls, sdk.ToolCall{
+			ID:   block.ID,
+			Type: block.Type,
+			Function: sdk.ToolCallFunction{
+				Name:      block.Name,
+				Arguments: string(block.Input),
+			},
+		})
+	}
+
+	return toolCalls
+}
+
+// messageDecoder converts the message_start, content_block_* and
+// message_delta events of a streamed message into chunks of a single choice.
+type messageDecoder struct {
+	usage   usage
+	hasText bool
+	// toolCalls are the tool_use blocks by block index.
+	toolCalls map[int]*toolUseBlock
+}
+
+type toolUseBlock struct {
+	index int
+	// input is the input sent with content_block_start, which is only used
+	// when no input_json_delta follows.
+	input    json.RawMessage
+	hasDelta bool
+}
+
+type streamEvent struct {
+	Type         string            `json:"type"`
+	Index        int               `json:"index"`
+	Message      *messagesResponse `json:"message"`
+	ContentBlock *contentBlock     `json:"content_block"`
+	Delta        struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+		StopReason  string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage *usage `json:"usage"`
+}
+
+func (decoder *messageDecoder) Chunks(data []byte) []sdk.StreamChunk {
+	var event streamEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil
+	}
+
+	switch event.Type {
+	case "message_start":
+		if event.Message == nil {
+			return nil
+		}
+		decoder.usage = event.Message.Usage
+		usage := decoder.usage.toUsage()
+		return []sdk.StreamChunk{{ID: event.Message.ID, Model: event.Message.Model, Role: event.Message.Role, Usage: &usage}}
+	case "content_block_start":
+		if event.ContentBlock == nil {
+			return nil
+		}
+		block := event.ContentBlock
+		switch block.Type {
+		case "text":
+			// Separate the texts of successive blocks, as in the completion
+			// of an unstreamed message.
+			text := block.Text
+			if decoder.hasText {
+				text = "\n" + text
+			}
+			decoder.hasText = true
+			return []sdk.StreamChunk{{Content: text}}
+		case "tool_use":
+			toolUse := &toolUseBlock{index: len(decoder.toolCalls), input: block.Input}
+			decoder.toolCalls[event.Index] = toolUse
+			return []sdk.StreamChunk{{ToolCalls: []sdk.ToolCallDelta{{
+				Index: toolUse.index,
+				ID:    block.ID,
+				Type:  block.Type,
+				Name:  block.Name,
+			}}}}
+		}
+	case "content_block_delta":
+		switch event.Delta.Type {
+		case "text_delta":
+			return []sdk.StreamChunk{{Content: event.Delta.Text}}
+		case "input_json_delta":
+			toolUse, ok := decoder.toolCalls[event.Index]
+			if !ok {
+				return nil
+			}
+			toolUse.hasDelta = true
+			return []sdk.StreamChunk{{ToolCalls: []sdk.ToolCallDelta{{Index: toolUse.index, Arguments: event.Delta.PartialJSON}}}}
+		}
+	case "content_block_stop":
+		toolUse, ok := decoder.toolCalls[event.Index]
+		if !ok || toolUse.hasDelta || len(toolUse.input) == 0 {
+			return nil
+		}
+		return []sdk.StreamChunk{{ToolCalls: []sdk.ToolCallDelta{{Index: toolUse.index, Arguments: string(toolUse.input)}}}}
+	case "message_delta":
+		chunk := sdk.StreamChunk{FinishReason: event.Delta.StopReason}
+		if event.Usage != nil {
+			decoder.usage.OutputTokens = event.Usage.OutputTokens
+			if event.Usage.InputTokens > 0 {
+				decoder.usage.InputTokens = event.Usage.InputTokens
+			}
+			usage := decoder.usage.toUsage()
+			chunk.Usage = &usage
+		}
+		return []sdk.StreamChunk{chunk}
+	}
+
+	return nil
+}
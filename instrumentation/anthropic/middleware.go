@@ -0,0 +1,54 @@
+// Package otelanthropic instruments github.com/anthropics/anthropic-sdk-go.
+//
+// Register the middleware once on the client and every Messages API call is
+// logged as an LLM span through the Traceloop SDK:
+//
+//	client := anthropic.NewClient(
+//		option.WithMiddleware(otelanthropic.Middleware(traceloop)),
+//	)
+package otelanthropic
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go/option"
+	sdk "github.com/traceloop/go-openllmetry/traceloop-sdk"
+	"github.com/traceloop/go-openllmetry/traceloop-sdk/llmhttp"
+)
+
+const vendor = "anthropic"
+
+type config struct {
+	workflowAttrs sdk.WorkflowAttributes
+}
+
+type Option func(*config)
+
+// WithWorkflowAttributes sets the workflow name and association properties
+// recorded on every LLM span created by the middleware.
+func WithWorkflowAttributes(attrs sdk.WorkflowAttributes) Option {
+	return func(c *config) {
+		c.workflowAttrs = attrs
+	}
+}
+
+func Middleware(traceloop *sdk.Traceloop, opts ...Option) option.Middleware {
+	cfg := config{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	middleware := llmhttp.Middleware(traceloop, cfg.workflowAttrs, endpointFor)
+	return func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		return middleware(req, next)
+	}
+}
+
+func endpointFor(req *http.Request) llmhttp.Endpoint {
+	if req.Method != http.MethodPost || !strings.HasSuffix(strings.TrimSuffix(req.URL.Path, "/"), "/messages") {
+		return nil
+	}
+
+	return messagesEndpoint{}
+}
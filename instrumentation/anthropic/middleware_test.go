@@ -0,0 +1,197 @@
+package otelanthropic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/traceloop/go-openllmetry/traceloop-sdk/tracelooptest"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) (anthropic.Client, *tracetest.InMemoryExporter) {
+	t.Helper()
+
+	server, tl, exporter := tracelooptest.NewServer(t, handler)
+
+	client := anthropic.NewClient(
+		option.WithAPIKey("test-key"),
+		option.WithBaseURL(server.URL),
+		option.WithMaxRetries(0),
+		option.WithMiddleware(Middleware(tl)),
+	)
+
+	return client, exporter
+}
+
+func TestMessagesMiddleware(t *testing.T) {
+	client, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{
+			"id": "msg_1",
+			"type": "message",
+			"role": "assistant",
+			"model": "claude-sonnet-4-5-20250929",
+			"content": [{"type": "text", "text": "It is sunny in Paris."}],
+			"stop_reason": "end_turn",
+			"usage": {
+				"input_tokens": 10,
+				"output_tokens": 8,
+				"cache_creation_input_tokens": 100,
+				"cache_read_input_tokens": 200
+			}
+		}`)
+	})
+
+	_, err := client.Messages.New(context.Background(), anthropic.MessageNewParams{
+		Model:     "claude-sonnet-4-5",
+		MaxTokens: 1024,
+		System:    []anthropic.TextBlockParam{{Text: "You are a weather bot."}},
+		Messages: []anthropic.MessageParam{
+			anthropic.NewUserMessage(
+				anthropic.NewTextBlock("Weather in Paris?"),
+				anthropic.NewImageBlockBase64("image/png", "bm90IHJlYWxseSBhIHBuZw=="),
+			),
+			anthropic.NewAssistantMessage(anthropic.NewToolUseBlock("toolu_1", map[string]string{"location": "Paris"}, "get_weather")),
+			anthropic.NewUserMessage(anthropic.NewToolResultBlock("toolu_1", "Sunny, 22C", false)),
+		},
+		Tools: []anthropic.ToolUnionParam{{
+			OfTool: &anthropic.ToolParam{
+				Name:        "get_weather",
+				Description: anthropic.String("Get the current weather"),
+				InputSchema: anthropic.ToolInputSchemaParam{
+					Properties: map[string]interface{}{"location": map[string]string{"type": "string"}},
+				},
+			},
+		}},
+		ToolChoice: anthropic.ToolChoiceUnionParam{OfAuto: &anthropic.ToolChoiceAutoParam{}},
+	})
+	if err != nil {
+		t.Fatalf("Messages.New failed: %v", err)
+	}
+
+	tracelooptest.AssertAttributes(t, tracelooptest.SpanAttributes(t, exporter), map[string]interface{}{
+		"llm.vendor":                            "anthropic",
+		"llm.request.max_tokens":                int64(1024),
+		"llm.request.tool_choice":               "auto",
+		"llm.request.type":                      "chat",
+		"llm.request.model":                     "claude-sonnet-4-5",
+		"llm.response.id":                       "msg_1",
+		"llm.response.model":                    "claude-sonnet-4-5-20250929",
+		"llm.prompts.0.role":                    "system",
+		"llm.prompts.0.content":                 "You are a weather bot.",
+		"llm.prompts.1.content":                 "Weather in Paris?",
+		"llm.prompts.1.content_parts.1.type":    "image",
+		"llm.prompts.1.content_parts.1.sha256":  "e90137d39de304eefbbe788bc535c7e82f27abbf8069505fbbd8a9dcdc4f2024",
+		"llm.prompts.2.role":                    "assistant",
+		"llm.prompts.2.tool_calls.0.id":         "toolu_1",
+		"llm.prompts.2.tool_calls.0.name":       "get_weather",
+		"llm.prompts.2.tool_calls.0.arguments":  `{"location":"Paris"}`,
+		"llm.prompts.3.content":                 "Sunny, 22C",
+		"llm.prompts.3.tool_call_id":            "toolu_1",
+		"llm.request.functions.0.name":          "get_weather",
+		"llm.completions.0.content":             "It is sunny in Paris.",
+		"llm.completions.0.finish_reason":       "end_turn",
+		"llm.usage.prompt_tokens":               int64(310),
+		"llm.usage.completion_tokens":           int64(8),
+		"llm.usage.total_tokens":                int64(318),
+		"llm.usage.cache_creation_input_tokens": int64(100),
+		"llm.usage.cache_read_input_tokens":     int64(200),
+	})
+}
+
+func TestMessagesStreamingMiddleware(t *testing.T) {
+	client, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range []string{
+			`{"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-5-20250929","content":[],"usage":{"input_tokens":25,"output_tokens":1}}}`,
+			`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Checking"}}`,
+			`{"type":"content_block_stop","index":0}`,
+			`{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{}}}`,
+			`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"location\":"}}`,
+			`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"\"Paris\"}"}}`,
+			`{"type":"content_block_stop","index":1}`,
+			`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":15}}`,
+			`{"type":"message_stop"}`,
+		} {
+			var eventType struct {
+				Type string `json:"type"`
+			}
+			json.Unmarshal([]byte(event), &eventType)
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", eventType.Type, event)
+		}
+	})
+
+	stream := client.Messages.NewStreaming(context.Background(), anthropic.MessageNewParams{
+		Model:     "claude-sonnet-4-5",
+		MaxTokens: 1024,
+		Messages: []anthropic.MessageParam{
+			anthropic.NewUserMessage(
+				anthropic.NewTextBlock("Weather in Paris?"),
+				anthropic.NewImageBlockBase64("image/png", "bm90IHJlYWxseSBhIHBuZw=="),
+			),
+		},
+	})
+	for stream.Next() {
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatalf("NewStreaming failed: %v", err)
+	}
+	stream.Close()
+
+	tracelooptest.AssertAttributes(t, tracelooptest.SpanAttributes(t, exporter), map[string]interface{}{
+		"llm.response.id":                          "msg_1",
+		"llm.response.model":                       "claude-sonnet-4-5-20250929",
+		"llm.completions.0.content":                "Checking",
+		"llm.completions.0.finish_reason":          "tool_use",
+		"llm.completions.0.tool_calls.0.id":        "toolu_1",
+		"llm.completions.0.tool_calls.0.arguments": `{"location":"Paris"}`,
+		"llm.usage.prompt_tokens":                  int64(25),
+		"llm.usage.completion_tokens":              int64(15),
+		"llm.is_streaming":                         true,
+	})
+}
+
+func TestMessagesMiddlewareError(t *testing.T) {
+	client, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, `{"type":"error","error":{"type":"invalid_request_error","message":"prompt is too long"}}`)
+	})
+
+	_, err := client.Messages.New(context.Background(), anthropic.MessageNewParams{
+		Model:     "claude-sonnet-4-5",
+		MaxTokens: 1024,
+		Messages: []anthropic.MessageParam{
+			anthropic.NewUserMessage(anthropic.NewTextBlock("Hi")),
+		},
+	})
+	if err == nil {
+		t.Fatal("Expected Messages.New to fail")
+	}
+	if !strings.Contains(err.Error(), "prompt is too long") {
+		t.Errorf("Expected the client to read the error body, got %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected the failed call's span to be ended, got %d spans", len(spans))
+	}
+	if spans[0].Status.Code != codes.Error {
+		t.Errorf("Expected the span status to be an error, got %v", spans[0].Status)
+	}
+	tracelooptest.AssertAttributes(t, tracelooptest.SpanAttributes(t, exporter), map[string]interface{}{
+		"llm.error.type": "context_length_exceeded",
+	})
+}
@@ -0,0 +1,308 @@
+package otelbedrock
+
+import (
+	"encoding/json"
+	"strings"
+
+	sdk "github.com/traceloop/go-openllmetry/traceloop-sdk"
+)
+
+// anthropicCodec decodes Claude's Messages API bodies, as well as the legacy
+// Text Completions bodies of Claude 2 and Claude Instant.
+type anthropicCodec struct{}
+
+type anthropicRequest struct {
+	Prompt        string             `json:"prompt"`
+	System        json.RawMessage    `json:"system"`
+	Messages      []anthropicMessage `json:"messages"`
+	Tools         []anthropicTool    `json:"tools"`
+	Temperature   *float32           `json:"temperature"`
+	TopP          *float32           `json:"top_p"`
+	StopSequences []string           `json:"stop_sequences"`
+	MaxTokens     int                `json:"max_tokens"`
+}
+
+type anthropicMessage struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+type anthropicTool struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	InputSchema interface{} `json:"input_schema"`
+}
+
+// anthropicBlock covers the text, tool_use and tool_result blocks.
+type anthropicBlock struct {
+	Type    string          `json:"type"`
+	Text    string          `json:"text"`
+	ID      string          `json:"id"`
+	Name    string          `json:"name"`
+	Input   json.RawMessage `json:"input"`
+	Content json.RawMessage `json:"content"`
+}
+
+type anthropicUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+type anthropicResponse struct {
+	ID         string           `json:"id"`
+	Model      string           `json:"model"`
+	Role       string           `json:"role"`
+	Content    []anthropicBlock `json:"content"`
+	Completion string           `json:"completion"`
+	StopReason string           `json:"stop_reason"`
+	Usage      anthropicUsage   `json:"usage"`
+}
+
+func (anthropicCodec) prompt(body []byte) (sdk.Prompt, error) {
+	var request anthropicRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		return sdk.Prompt{}, err
+	}
+
+	prompt := sdk.Prompt{
+		Mode:        "chat",
+		Temperature: request.Temperature,
+		TopP:        request.TopP,
+		Stop:        request.StopSequences,
+		MaxTokens:   request.MaxTokens,
+	}
+
+	if request.Prompt != "" {
+		prompt.Mode = "completion"
+		prompt.Messages = textMessages(request.Prompt)
+		return prompt, nil
+	}
+
+	if system := anthropicText(parseAnthropicBlocks(request.System)); system != "" {
+		prompt.Messages = append(prompt.Messages, sdk.Message{
+			Index:   0,
+			Role:    "system",
+			Content: system,
+		})
+	}
+
+	for _, m := range request.Messages {
+		blocks := parseAnthropicBlocks(m.Content)
+		prompt.Messages = append(prompt.Messages, sdk.Message{
+			Index:     len(prompt.Messages),
+			Role:      m.Role,
+			Content:   anthropicText(blocks),
+			ToolCalls: anthropicToolCalls(blocks),
+		})
+	}
+
+	for _, t := range request.Tools {
+		prompt.Tools = append(prompt.Tools, sdk.Tool{
+			Type: "function",
+			Function: sdk.ToolFunction{
+				Name:        t.Name,
+				Description: t.Description,
+				Parameters:  t.InputSchema,
+			},
+		})
+	}
+
+	return prompt, nil
+}
+
+func (anthropicCodec) completion(body []byte) (sdk.Completion, sdk.Usage, error) {
+	var response anthropicResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return sdk.Completion{}, sdk.Usage{}, err
+	}
+
+	return response.toCompletion(), response.Usage.toUsage(), nil
+}
+
+func (anthropicCodec) bodyDecoder() bodyDecoder {
+	return &anthropicDecoder{toolCalls: make(map[int]*anthropicToolUse)}
+}
+
+func (response anthropicResponse) toCompletion() sdk.Completion {
+	message := sdk.Message{
+		Index:        0,
+		Role:         response.Role,
+		Content:      anthropicText(response.Content),
+		ToolCalls:    anthropicToolCalls(response.Content),
+		FinishReason: response.StopReason,
+	}
+	if message.Role == "" {
+		message.Role = "assistant"
+	}
+	if response.Content == nil {
+		message.Content = response.Completion
+	}
+
+	return sdk.Completion{
+		ID:       response.ID,
+		Model:    response.Model,
+		Messages: []sdk.Message{message},
+	}
+}
+
+func (u anthropicUsage) toUsage() sdk.Usage {
+	return tokenUsage(u.InputTokens, u.OutputTokens, u.CacheReadInputTokens, u.CacheCreationInputTokens)
+}
+
+// parseAnthropicBlocks decodes content that is either a plain string or an
+// array of content blocks.
+func parseAnthropicBlocks(raw json.RawMessage) []anthropicBlock {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return []anthropicBlock{{Type: "text", Text: text}}
+	}
+
+	var blocks []anthropicBlock
+	json.Unmarshal(raw, &blocks)
+	return blocks
+}
+
+func anthropicText(blocks []anthropicBlock) string {
+	var texts []string
+	for _, block := range blocks {
+		switch block.Type {
+		case "text":
+			texts = append(texts, block.Text)
+		case "tool_result":
+			texts = append(texts, anthropicText(parseAnthropicBlocks(block.Content)))
+		}
+	}
+
+	return strings.Join(texts, "\n")
+}
+
+func anthropicToolCalls(blocks []anthropicBlock) []sdk.ToolCall {
+	var toolCalls []sdk.ToolCall
+	for _, block := range blocks {
+		if block.Type != "tool_use" {
+			continue
+		}
+		toolCalls = append(toolCalls, sdk.ToolCall{
+			ID:   block.ID,
+			Type: block.Type,
+			Function: sdk.ToolCallFunction{
+				Name:      block.Name,
+				Arguments: string(block.Input),
+			},
+		})
+	}
+
+	return toolCalls
+}
+
+// anthropicDecoder converts the message_start, content_block_* and
+// message_delta stream events, or the chunks of a legacy text completion,
+// into chunks of a single choice.
+type anthropicDecoder struct {
+	usage   anthropicUsage
+	hasText bool
+	// toolCalls are the tool_use blocks by block index.
+	toolCalls map[int]*anthropicToolUse
+}
+
+type anthropicToolUse struct {
+	index int
+	// input is the input sent with content_block_start, which is only used
+	// when no input_json_delta follows.
+	input    json.RawMessage
+	hasDelta bool
+}
+
+type anthropicStreamEvent struct {
+	Type         string             `json:"type"`
+	Index        int                `json:"index"`
+	Message      *anthropicResponse `json:"message"`
+	ContentBlock *anthropicBlock    `json:"content_block"`
+	Delta        struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+		StopReason  string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage      *anthropicUsage `json:"usage"`
+	Completion string          `json:"completion"`
+	StopReason string          `json:"stop_reason"`
+}
+
+func (decoder *anthropicDecoder) chunks(body []byte) []sdk.StreamChunk {
+	var event anthropicStreamEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil
+	}
+
+	switch event.Type {
+	case "message_start":
+		if event.Message == nil {
+			return nil
+		}
+		decoder.usage = event.Message.Usage
+		usage := decoder.usage.toUsage()
+		return []sdk.StreamChunk{{ID: event.Message.ID, Model: event.Message.Model, Role: event.Message.Role, Usage: &usage}}
+	case "content_block_start":
+		if event.ContentBlock == nil {
+			return nil
+		}
+		block := event.ContentBlock
+		switch block.Type {
+		case "text":
+			// Separate the texts of successive blocks, as in the completion
+			// of an unstreamed message.
+			text := block.Text
+			if decoder.hasText {
+				text = "\n" + text
+			}
+			decoder.hasText = true
+			return []sdk.StreamChunk{{Content: text}}
+		case "tool_use":
+			toolUse := &anthropicToolUse{index: len(decoder.toolCalls), input: block.Input}
+			decoder.toolCalls[event.Index] = toolUse
+			return []sdk.StreamChunk{{ToolCalls: []sdk.ToolCallDelta{{
+				Index: toolUse.index,
+				ID:    block.ID,
+				Type:  block.Type,
+				Name:  block.Name,
+			}}}}
+		}
+	case "content_block_delta":
+		switch event.Delta.Type {
+		case "text_delta":
+			return []sdk.StreamChunk{{Content: event.Delta.Text}}
+		case "input_json_delta":
+			toolUse, ok := decoder.toolCalls[event.Index]
+			if !ok {
+				return nil
+			}
+			toolUse.hasDelta = true
+			return []sdk.StreamChunk{{ToolCalls: []sdk.ToolCallDelta{{Index: toolUse.index, Arguments: event.Delta.PartialJSON}}}}
+		}
+	case "content_block_stop":
+		toolUse, ok := decoder.toolCalls[event.Index]
+		if !ok || toolUse.hasDelta || len(toolUse.input) == 0 {
+			return nil
+		}
+		return []sdk.StreamChunk{{ToolCalls: []sdk.ToolCallDelta{{Index: toolUse.index, Arguments: string(toolUse.input)}}}}
+	case "message_delta":
+		chunk := sdk.StreamChunk{FinishReason: event.Delta.StopReason}
+		if event.Usage != nil {
+			decoder.usage.OutputTokens = event.Usage.OutputTokens
+			usage := decoder.usage.toUsage()
+			chunk.Usage = &usage
+		}
+		return []sdk.StreamChunk{chunk}
+	case "":
+		return []sdk.StreamChunk{{Content: event.Completion, FinishReason: event.StopReason}}
+	}
+
+	return nil
+}
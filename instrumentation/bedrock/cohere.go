@@ -0,0 +1,143 @@
+package otelbedrock
+
+import (
+	"encoding/json"
+	"strings"
+
+	sdk "github.com/traceloop/go-openllmetry/traceloop-sdk"
+)
+
+// cohereCodec decodes the Cohere Command bodies, which are either a text
+// prompt (Command, Command Light) or a chat message with its history
+// (Command R, Command R+).
+type cohereCodec struct{}
+
+type cohereRequest struct {
+	Prompt      string `json:"prompt"`
+	Message     string `json:"message"`
+	Preamble    string `json:"preamble"`
+	ChatHistory []struct {
+		Role    string `json:"role"`
+		Message string `json:"message"`
+	} `json:"chat_history"`
+	Temperature   *float32 `json:"temperature"`
+	P             *float32 `json:"p"`
+	StopSequences []string `json:"stop_sequences"`
+	MaxTokens     int      `json:"max_tokens"`
+}
+
+type cohereGeneration struct {
+	Text         string `json:"text"`
+	FinishReason string `json:"finish_reason"`
+}
+
+// cohereResponse holds either the generations of a text prompt or the
+// reply to a chat message.
+type cohereResponse struct {
+	Generations  []cohereGeneration `json:"generations"`
+	Text         string             `json:"text"`
+	FinishReason string             `json:"finish_reason"`
+	Meta         struct {
+		BilledUnits struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"billed_units"`
+	} `json:"meta"`
+}
+
+func (cohereCodec) prompt(body []byte) (sdk.Prompt, error) {
+	var request cohereRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		return sdk.Prompt{}, err
+	}
+
+	prompt := sdk.Prompt{
+		Mode:        "completion",
+		Temperature: request.Temperature,
+		TopP:        request.P,
+		Stop:        request.StopSequences,
+		MaxTokens:   request.MaxTokens,
+	}
+
+	if request.Prompt != "" {
+		prompt.Messages = textMessages(request.Prompt)
+		return prompt, nil
+	}
+
+	prompt.Mode = "chat"
+	if request.Preamble != "" {
+		prompt.Messages = append(prompt.Messages, sdk.Message{Index: 0, Role: "system", Content: request.Preamble})
+	}
+	for _, m := range request.ChatHistory {
+		prompt.Messages = append(prompt.Messages, sdk.Message{
+			Index:   len(prompt.Messages),
+			Role:    cohereRole(m.Role),
+			Content: m.Message,
+		})
+	}
+	prompt.Messages = append(prompt.Messages, sdk.Message{
+		Index:   len(prompt.Messages),
+		Role:    "user",
+		Content: request.Message,
+	})
+
+	return prompt, nil
+}
+
+// cohereRole maps the chat history roles (USER, CHATBOT, SYSTEM) to the
+// ones used by the other vendors.
+func cohereRole(role string) string {
+	switch strings.ToUpper(role) {
+	case "CHATBOT":
+		return "assistant"
+	default:
+		return strings.ToLower(role)
+	}
+}
+
+func (cohereCodec) completion(body []byte) (sdk.Completion, sdk.Usage, error) {
+	var response cohereResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return sdk.Completion{}, sdk.Usage{}, err
+	}
+
+	usage := tokenUsage(response.Meta.BilledUnits.InputTokens, response.Meta.BilledUnits.OutputTokens, 0, 0)
+	if len(response.Generations) == 0 {
+		return textCompletion([]string{response.Text}, []string{response.FinishReason}), usage, nil
+	}
+
+	var texts, finishReasons []string
+	for _, generation := range response.Generations {
+		texts = append(texts, generation.Text)
+		finishReasons = append(finishReasons, generation.FinishReason)
+	}
+
+	return textCompletion(texts, finishReasons), usage, nil
+}
+
+func (cohereCodec) bodyDecoder() bodyDecoder {
+	return cohereDecoder{}
+}
+
+// cohereDecoder converts the streamed chunks of generated text. Chat streams
+// tag each chunk with an event type and only text-generation events carry
+// reply text.
+type cohereDecoder struct{}
+
+func (cohereDecoder) chunks(body []byte) []sdk.StreamChunk {
+	var event struct {
+		EventType    string `json:"event_type"`
+		Text         string `json:"text"`
+		FinishReason string `json:"finish_reason"`
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil
+	}
+
+	chunk := sdk.StreamChunk{FinishReason: event.FinishReason}
+	if event.EventType == "" || event.EventType == "text-generation" {
+		chunk.Content = event.Text
+	}
+
+	return []sdk.StreamChunk{chunk}
+}
@@ -0,0 +1,326 @@
+package otelbedrock
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/document"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	sdk "github.com/traceloop/go-openllmetry/traceloop-sdk"
+)
+
+// converseOperation covers Converse and ConverseStream, which share the
+// model-agnostic request shape.
+type converseOperation struct {
+	modelID         *string
+	system          []types.SystemContentBlock
+	messages        []types.Message
+	inferenceConfig *types.InferenceConfiguration
+	toolConfig      *types.ToolConfiguration
+	stream          bool
+}
+
+func (op converseOperation) prompt() (sdk.Prompt, error) {
+	prompt := sdk.Prompt{
+		Vendor: vendor,
+		Mode:   "chat",
+		Model:  aws.ToString(op.modelID),
+	}
+
+	if config := op.inferenceConfig; config != nil {
+		prompt.Temperature = config.Temperature
+		prompt.TopP = config.TopP
+		prompt.Stop = config.StopSequences
+		prompt.MaxTokens = int(aws.ToInt32(config.MaxTokens))
+	}
+	if op.toolConfig != nil {
+		prompt.ToolChoice = toolChoice(op.toolConfig.ToolChoice)
+	}
+
+	var systemTexts []string
+	for _, block := range op.system {
+		if text, ok := block.(*types.SystemContentBlockMemberText); ok {
+			systemTexts = append(systemTexts, text.Value)
+		}
+	}
+	if len(systemTexts) > 0 {
+		prompt.Messages = append(prompt.Messages, sdk.Message{
+			Index:   0,
+			Role:    "system",
+			Content: strings.Join(systemTexts, "\n"),
+		})
+	}
+
+	for _, message := range op.messages {
+		prompt.Messages = append(prompt.Messages, converseMessage(len(prompt.Messages), message))
+	}
+
+	if op.toolConfig != nil {
+		for _, tool := range op.toolConfig.Tools {
+			spec, ok := tool.(*types.ToolMemberToolSpec)
+			if !ok {
+				continue
+			}
+
+			var parameters interface{}
+			if schema, ok := spec.Value.InputSchema.(*types.ToolInputSchemaMemberJson); ok {
+				parameters = documentJSON(schema.Value)
+			}
+
+			prompt.Tools = append(prompt.Tools, sdk.Tool{
+				Type: "function",
+				Function: sdk.ToolFunction{
+					Name:        aws.ToString(spec.Value.Name),
+					Description: aws.ToString(spec.Value.Description),
+					Parameters:  parameters,
+				},
+			})
+		}
+	}
+
+	return prompt, nil
+}
+
+func (op converseOperation) completion(result interface{}) (sdk.Completion, sdk.Usage, error) {
+	output, ok := result.(*bedrockruntime.ConverseOutput)
+	if !ok {
+		return sdk.Completion{}, sdk.Usage{}, fmt.Errorf("unexpected Converse result %T", result)
+	}
+
+	completion := sdk.Completion{Model: aws.ToString(op.modelID)}
+	if message, ok := output.Output.(*types.ConverseOutputMemberMessage); ok {
+		completionMessage := converseMessage(0, message.Value)
+		completionMessage.FinishReason = string(output.StopReason)
+		completion.Messages = append(completion.Messages, completionMessage)
+	}
+
+	var usage sdk.Usage
+	if output.Usage != nil {
+		usage = tokenUsage(
+			int(aws.ToInt32(output.Usage.InputTokens)),
+			int(aws.ToInt32(output.Usage.OutputTokens)),
+			int(aws.ToInt32(output.Usage.CacheReadInputTokens)),
+			int(aws.ToInt32(output.Usage.CacheWriteInputTokens)),
+		)
+	}
+
+	return completion, usage, nil
+}
+
+func (op converseOperation) streamDecoder() streamDecoder {
+	if !op.stream {
+		return nil
+	}
+
+	return &converseStreamDecoder{toolCalls: make(map[int]int)}
+}
+
+func converseMessage(index int, message types.Message) sdk.Message {
+	var texts []string
+	var parts []sdk.ContentPart
+	var toolCalls []sdk.ToolCall
+	var toolCallID string
+	hasBinary := false
+	for _, block := range message.Content {
+		switch block := block.(type) {
+		case *types.ContentBlockMemberText:
+			texts = append(texts, block.Value)
+			parts = append(parts, sdk.ContentPart{Type: sdk.ContentPartText, Text: block.Value})
+		case *types.ContentBlockMemberImage:
+			hasBinary = true
+			part := sdk.ContentPart{Type: sdk.ContentPartImage, MimeType: "image/" + string(block.Value.Format)}
+			switch source := block.Value.Source.(type) {
+			case *types.ImageSourceMemberBytes:
+				part.Data = source.Value
+			case *types.ImageSourceMemberS3Location:
+				part.URL = aws.ToString(source.Value.Uri)
+			}
+			parts = append(parts, part)
+		case *types.ContentBlockMemberAudio:
+			hasBinary = true
+			part := sdk.ContentPart{Type: sdk.ContentPartAudio, MimeType: "audio/" + string(block.Value.Format)}
+			switch source := block.Value.Source.(type) {
+			case *types.AudioSourceMemberBytes:
+				part.Data = source.Value
+			case *types.AudioSourceMemberS3Location:
+				part.URL = aws.ToString(source.Value.Uri)
+			}
+			parts = append(parts, part)
+		case *types.ContentBlockMemberDocument:
+			hasBinary = true
+			part := sdk.ContentPart{
+				Type:     sdk.ContentPartFile,
+				MimeType: mime.TypeByExtension("." + string(block.Value.Format)),
+				Filename: aws.ToString(block.Value.Name),
+			}
+			switch source := block.Value.Source.(type) {
+			case *types.DocumentSourceMemberBytes:
+				part.Data = source.Value
+			case *types.DocumentSourceMemberText:
+				part.Data = []byte(source.Value)
+			case *types.DocumentSourceMemberS3Location:
+				part.URL = aws.ToString(source.Value.Uri)
+			}
+			parts = append(parts, part)
+		case *types.ContentBlockMemberToolUse:
+			toolCalls = append(toolCalls, sdk.ToolCall{
+				ID:   aws.ToString(block.Value.ToolUseId),
+				Type: "tool_use",
+				Function: sdk.ToolCallFunction{
+					Name:      aws.ToString(block.Value.Name),
+					Arguments: string(documentJSON(block.Value.Input)),
+				},
+			})
+		case *types.ContentBlockMemberToolResult:
+			if toolCallID == "" {
+				toolCallID = aws.ToString(block.Value.ToolUseId)
+			}
+			for _, content := range block.Value.Content {
+				switch content := content.(type) {
+				case *types.ToolResultContentBlockMemberText:
+					texts = append(texts, content.Value)
+				case *types.ToolResultContentBlockMemberJson:
+					texts = append(texts, string(documentJSON(content.Value)))
+				}
+			}
+		}
+	}
+
+	// Text-only content is already recorded as the message's content.
+	if !hasBinary {
+		parts = nil
+	}
+
+	return sdk.Message{
+		Index:        index,
+		Role:         string(message.Role),
+		Content:      strings.Join(texts, "\n"),
+		ContentParts: parts,
+		ToolCalls:    toolCalls,
+		ToolCallID:   toolCallID,
+	}
+}
+
+// documentJSON renders a Smithy document, such as a tool's input schema or
+// arguments, as JSON.
+func documentJSON(doc document.Interface) json.RawMessage {
+	if doc == nil {
+		return nil
+	}
+
+	data, err := doc.MarshalSmithyDocument()
+	if err != nil {
+		fmt.Printf("Failed to marshal document: %v\n", err)
+		return nil
+	}
+
+	return data
+}
+
+// converseStreamDecoder converts the messageStart, contentBlock*,
+// messageStop and metadata events of ConverseStream into chunks of a single
+// choice.
+type converseStreamDecoder struct {
+	// toolCalls maps the content block index of tool uses to their index
+	// among the tool calls of the message.
+	toolCalls map[int]int
+	textBlock int
+	hasText   bool
+}
+
+type converseStreamEvent struct {
+	Role              string `json:"role"`
+	ContentBlockIndex int    `json:"contentBlockIndex"`
+	Start             struct {
+		ToolUse *struct {
+			ToolUseID string `json:"toolUseId"`
+			Name      string `json:"name"`
+		} `json:"toolUse"`
+	} `json:"start"`
+	Delta struct {
+		Text    string `json:"text"`
+		ToolUse *struct {
+			Input string `json:"input"`
+		} `json:"toolUse"`
+	} `json:"delta"`
+	StopReason string `json:"stopReason"`
+	Usage      *struct {
+		InputTokens           int `json:"inputTokens"`
+		OutputTokens          int `json:"outputTokens"`
+		CacheReadInputTokens  int `json:"cacheReadInputTokens"`
+		CacheWriteInputTokens int `json:"cacheWriteInputTokens"`
+	} `json:"usage"`
+}
+
+func (decoder *converseStreamDecoder) chunks(eventType string, payload []byte) []sdk.StreamChunk {
+	var event converseStreamEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil
+	}
+
+	switch eventType {
+	case "messageStart":
+		return []sdk.StreamChunk{{Role: event.Role}}
+	case "contentBlockStart":
+		if event.Start.ToolUse == nil {
+			return nil
+		}
+		index := len(decoder.toolCalls)
+		decoder.toolCalls[event.ContentBlockIndex] = index
+		return []sdk.StreamChunk{{ToolCalls: []sdk.ToolCallDelta{{
+			Index: index,
+			ID:    event.Start.ToolUse.ToolUseID,
+			Type:  "tool_use",
+			Name:  event.Start.ToolUse.Name,
+		}}}}
+	case "contentBlockDelta":
+		if event.Delta.ToolUse != nil {
+			index, ok := decoder.toolCalls[event.ContentBlockIndex]
+			if !ok {
+				return nil
+			}
+			return []sdk.StreamChunk{{ToolCalls: []sdk.ToolCallDelta{{Index: index, Arguments: event.Delta.ToolUse.Input}}}}
+		}
+		if event.Delta.Text == "" {
+			return nil
+		}
+		// Separate the texts of successive blocks, as in the completion of
+		// an unstreamed call.
+		text := event.Delta.Text
+		if decoder.hasText && decoder.textBlock != event.ContentBlockIndex {
+			text = "\n" + text
+		}
+		decoder.hasText = true
+		decoder.textBlock = event.ContentBlockIndex
+		return []sdk.StreamChunk{{Content: text}}
+	case "messageStop":
+		return []sdk.StreamChunk{{FinishReason: event.StopReason}}
+	case "metadata":
+		if event.Usage == nil {
+			return nil
+		}
+		usage := tokenUsage(event.Usage.InputTokens, event.Usage.OutputTokens, event.Usage.CacheReadInputTokens, event.Usage.CacheWriteInputTokens)
+		return []sdk.StreamChunk{{Usage: &usage}}
+	}
+
+	return nil
+}
+
+// toolChoice returns "auto" or "any", or the name of the tool the model is
+// forced to call.
+func toolChoice(choice types.ToolChoice) string {
+	switch choice := choice.(type) {
+	case *types.ToolChoiceMemberAuto:
+		return "auto"
+	case *types.ToolChoiceMemberAny:
+		return "any"
+	case *types.ToolChoiceMemberTool:
+		return aws.ToString(choice.Value.Name)
+	default:
+		return ""
+	}
+}
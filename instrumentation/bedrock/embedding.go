@@ -0,0 +1,130 @@
+package otelbedrock
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	sdk "github.com/traceloop/go-openllmetry/traceloop-sdk"
+)
+
+// isEmbeddingModel reports whether InvokeModel calls the model to embed texts
+// rather than to generate them.
+func isEmbeddingModel(modelID string) bool {
+	if i := strings.LastIndex(modelID, "/"); i >= 0 {
+		modelID = modelID[i+1:]
+	}
+
+	return strings.Contains(modelID, "embed")
+}
+
+// embeddingOperation decodes the Amazon Titan Embeddings bodies, which embed
+// a single inputText, and the Cohere Embed bodies, which embed a list of
+// texts.
+type embeddingOperation struct {
+	modelID string
+	body    []byte
+}
+
+func newEmbeddingOperation(input *bedrockruntime.InvokeModelInput) embeddingOperation {
+	return embeddingOperation{
+		modelID: aws.ToString(input.ModelId),
+		body:    input.Body,
+	}
+}
+
+type embeddingRequest struct {
+	InputText       string   `json:"inputText"`
+	Dimensions      int      `json:"dimensions"`
+	EmbeddingTypes  []string `json:"embeddingTypes"`
+	Texts           []string `json:"texts"`
+	OutputDimension int      `json:"output_dimension"`
+	CohereTypes     []string `json:"embedding_types"`
+}
+
+func (op embeddingOperation) prompt() (sdk.EmbeddingPrompt, error) {
+	var request embeddingRequest
+	if err := json.Unmarshal(op.body, &request); err != nil {
+		return sdk.EmbeddingPrompt{}, err
+	}
+
+	prompt := sdk.EmbeddingPrompt{
+		Vendor:     vendor,
+		Model:      op.modelID,
+		Inputs:     request.Texts,
+		Dimensions: request.Dimensions,
+	}
+	if request.InputText != "" {
+		prompt.Inputs = []string{request.InputText}
+	}
+	if request.OutputDimension != 0 {
+		prompt.Dimensions = request.OutputDimension
+	}
+
+	formats := request.EmbeddingTypes
+	if len(request.CohereTypes) > 0 {
+		formats = request.CohereTypes
+	}
+	prompt.EncodingFormat = strings.Join(formats, ",")
+
+	return prompt, nil
+}
+
+// embeddingResponse covers both families. Titan returns a single embedding,
+// while Cohere returns a list of embeddings, keyed by type when the request
+// set embedding_types.
+type embeddingResponse struct {
+	Embedding           []float64       `json:"embedding"`
+	InputTextTokenCount int             `json:"inputTextTokenCount"`
+	Embeddings          json.RawMessage `json:"embeddings"`
+}
+
+func (op embeddingOperation) embeddings(result interface{}) (sdk.Embeddings, sdk.Usage, error) {
+	output, ok := result.(*bedrockruntime.InvokeModelOutput)
+	if !ok {
+		return sdk.Embeddings{}, sdk.Usage{}, fmt.Errorf("unexpected InvokeModel result %T", result)
+	}
+
+	var response embeddingResponse
+	if err := json.Unmarshal(output.Body, &response); err != nil {
+		return sdk.Embeddings{}, sdk.Usage{}, err
+	}
+
+	embeddings := sdk.Embeddings{Model: op.modelID}
+	if len(response.Embedding) > 0 {
+		embeddings.Count = 1
+		embeddings.Dimensions = len(response.Embedding)
+	} else {
+		vectors := embeddingVectors(response.Embeddings)
+		embeddings.Count = len(vectors)
+		if len(vectors) > 0 {
+			embeddings.Dimensions = len(vectors[0])
+		}
+	}
+
+	return embeddings, tokenUsage(response.InputTextTokenCount, 0, 0, 0), nil
+}
+
+// embeddingVectors reads the Cohere embeddings, given either as a list of
+// vectors or as lists of vectors by embedding type, of which the first type
+// found is used.
+func embeddingVectors(raw json.RawMessage) [][]json.Number {
+	var vectors [][]json.Number
+	if err := json.Unmarshal(raw, &vectors); err == nil {
+		return vectors
+	}
+
+	var byType map[string][][]json.Number
+	if err := json.Unmarshal(raw, &byType); err != nil {
+		return nil
+	}
+	for _, embeddingType := range []string{"float", "int8", "uint8", "binary", "ubinary"} {
+		if vectors, ok := byType[embeddingType]; ok {
+			return vectors
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package otelbedrock
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	sdk "github.com/traceloop/go-openllmetry/traceloop-sdk"
+)
+
+// modelCodec decodes the native request and response bodies that a model
+// family accepts through InvokeModel.
+type modelCodec interface {
+	prompt(body []byte) (sdk.Prompt, error)
+	completion(body []byte) (sdk.Completion, sdk.Usage, error)
+	bodyDecoder() bodyDecoder
+}
+
+// bodyDecoder converts the response body chunks of
+// InvokeModelWithResponseStream into completion chunks.
+type bodyDecoder interface {
+	chunks(body []byte) []sdk.StreamChunk
+}
+
+// codecFor picks the codec from the provider in the model ID, which is
+// either a base model ID such as "anthropic.claude-3-haiku-20240307-v1:0",
+// a cross-region inference profile such as "us.anthropic.claude-3-haiku..."
+// or an ARN ending in one of them.
+func codecFor(modelID string) modelCodec {
+	if i := strings.LastIndex(modelID, "/"); i >= 0 {
+		modelID = modelID[i+1:]
+	}
+
+	switch {
+	case hasProvider(modelID, "anthropic"):
+		return anthropicCodec{}
+	case hasProvider(modelID, "amazon") && strings.Contains(modelID, "titan"):
+		return titanCodec{}
+	case hasProvider(modelID, "meta"):
+		return llamaCodec{}
+	case hasProvider(modelID, "mistral"):
+		return mistralCodec{}
+	case hasProvider(modelID, "cohere"):
+		return cohereCodec{}
+	default:
+		return unknownCodec{}
+	}
+}
+
+func hasProvider(modelID, provider string) bool {
+	return strings.HasPrefix(modelID, provider+".") || strings.Contains(modelID, "."+provider+".")
+}
+
+type invokeOperation struct {
+	modelID string
+	body    []byte
+	codec   modelCodec
+	stream  bool
+}
+
+func newInvokeOperation(modelID *string, body []byte, stream bool) invokeOperation {
+	return invokeOperation{
+		modelID: aws.ToString(modelID),
+		body:    body,
+		codec:   codecFor(aws.ToString(modelID)),
+		stream:  stream,
+	}
+}
+
+func (op invokeOperation) prompt() (sdk.Prompt, error) {
+	prompt, err := op.codec.prompt(op.body)
+	if err != nil {
+		return sdk.Prompt{}, err
+	}
+
+	prompt.Vendor = vendor
+	prompt.Model = op.modelID
+	return prompt, nil
+}
+
+func (op invokeOperation) completion(result interface{}) (sdk.Completion, sdk.Usage, error) {
+	output, ok := result.(*bedrockruntime.InvokeModelOutput)
+	if !ok {
+		return sdk.Completion{}, sdk.Usage{}, fmt.Errorf("unexpected InvokeModel result %T", result)
+	}
+
+	return op.codec.completion(output.Body)
+}
+
+func (op invokeOperation) streamDecoder() streamDecoder {
+	if !op.stream {
+		return nil
+	}
+
+	return &invokeStreamDecoder{body: op.codec.bodyDecoder()}
+}
+
+// invokeStreamDecoder unwraps the chunk events of
+// InvokeModelWithResponseStream. Bedrock appends its own token counts to the
+// last chunk, which are used when the model family does not report usage.
+type invokeStreamDecoder struct {
+	body      bodyDecoder
+	hasTokens bool
+}
+
+type invocationMetrics struct {
+	Metrics *struct {
+		InputTokenCount  int `json:"inputTokenCount"`
+		OutputTokenCount int `json:"outputTokenCount"`
+	} `json:"amazon-bedrock-invocationMetrics"`
+}
+
+func (decoder *invokeStreamDecoder) chunks(eventType string, payload []byte) []sdk.StreamChunk {
+	if eventType != "chunk" {
+		return nil
+	}
+
+	var part struct {
+		Bytes []byte `json:"bytes"`
+	}
+	if err := json.Unmarshal(payload, &part); err != nil {
+		return nil
+	}
+
+	chunks := decoder.body.chunks(part.Bytes)
+	for _, chunk := range chunks {
+		if chunk.Usage != nil && chunk.Usage.TotalTokens > 0 {
+			decoder.hasTokens = true
+		}
+	}
+
+	var metrics invocationMetrics
+	if err := json.Unmarshal(part.Bytes, &metrics); err == nil && metrics.Metrics != nil && !decoder.hasTokens {
+		usage := tokenUsage(metrics.Metrics.InputTokenCount, metrics.Metrics.OutputTokenCount, 0, 0)
+		chunks = append(chunks, sdk.StreamChunk{Usage: &usage})
+	}
+
+	return chunks
+}
+
+// unknownCodec traces models whose body format is not known, recording
+// only the token usage Bedrock reports for every model.
+type unknownCodec struct{}
+
+func (unknownCodec) prompt(body []byte) (sdk.Prompt, error) {
+	return sdk.Prompt{Mode: "chat"}, nil
+}
+
+func (unknownCodec) completion(body []byte) (sdk.Completion, sdk.Usage, error) {
+	return sdk.Completion{}, sdk.Usage{}, nil
+}
+
+func (unknownCodec) bodyDecoder() bodyDecoder {
+	return unknownDecoder{}
+}
+
+type unknownDecoder struct{}
+
+func (unknownDecoder) chunks(body []byte) []sdk.StreamChunk {
+	return nil
+}
+
+// textMessages wraps a raw prompt in a single user message.
+func textMessages(text string) []sdk.Message {
+	return []sdk.Message{{Index: 0, Role: "user", Content: text}}
+}
+
+// textCompletion builds a completion with one assistant message per
+// generated text.
+func textCompletion(texts []string, finishReasons []string) sdk.Completion {
+	var messages []sdk.Message
+	for i, text := range texts {
+		message := sdk.Message{Index: i, Role: "assistant", Content: text}
+		if i < len(finishReasons) {
+			message.FinishReason = finishReasons[i]
+		}
+		messages = append(messages, message)
+	}
+
+	return sdk.Completion{Messages: messages}
+}
@@ -0,0 +1,84 @@
+package otelbedrock
+
+import (
+	"encoding/json"
+
+	sdk "github.com/traceloop/go-openllmetry/traceloop-sdk"
+)
+
+// llamaCodec decodes the Meta Llama bodies, whose prompt is already
+// formatted with the model's chat template.
+type llamaCodec struct{}
+
+type llamaRequest struct {
+	Prompt      string   `json:"prompt"`
+	Temperature *float32 `json:"temperature"`
+	TopP        *float32 `json:"top_p"`
+	MaxGenLen   int      `json:"max_gen_len"`
+}
+
+type llamaResponse struct {
+	Generation           string `json:"generation"`
+	PromptTokenCount     int    `json:"prompt_token_count"`
+	GenerationTokenCount int    `json:"generation_token_count"`
+	StopReason           string `json:"stop_reason"`
+}
+
+func (llamaCodec) prompt(body []byte) (sdk.Prompt, error) {
+	var request llamaRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		return sdk.Prompt{}, err
+	}
+
+	return sdk.Prompt{
+		Mode:        "completion",
+		Temperature: request.Temperature,
+		TopP:        request.TopP,
+		MaxTokens:   request.MaxGenLen,
+		Messages:    textMessages(request.Prompt),
+	}, nil
+}
+
+func (llamaCodec) completion(body []byte) (sdk.Completion, sdk.Usage, error) {
+	var response llamaResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return sdk.Completion{}, sdk.Usage{}, err
+	}
+
+	completion := textCompletion([]string{response.Generation}, []string{response.StopReason})
+	return completion, tokenUsage(response.PromptTokenCount, response.GenerationTokenCount, 0, 0), nil
+}
+
+func (llamaCodec) bodyDecoder() bodyDecoder {
+	return &llamaDecoder{}
+}
+
+// llamaDecoder converts the streamed generation. The prompt token count
+// arrives with the first chunk and the generation token count grows with
+// every chunk.
+type llamaDecoder struct {
+	promptTokens     int
+	generationTokens int
+}
+
+func (decoder *llamaDecoder) chunks(body []byte) []sdk.StreamChunk {
+	var event llamaResponse
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil
+	}
+
+	if event.PromptTokenCount > 0 {
+		decoder.promptTokens = event.PromptTokenCount
+	}
+	if event.GenerationTokenCount > 0 {
+		decoder.generationTokens = event.GenerationTokenCount
+	}
+
+	chunk := sdk.StreamChunk{Content: event.Generation, FinishReason: event.StopReason}
+	if event.PromptTokenCount > 0 || event.GenerationTokenCount > 0 {
+		usage := tokenUsage(decoder.promptTokens, decoder.generationTokens, 0, 0)
+		chunk.Usage = &usage
+	}
+
+	return []sdk.StreamChunk{chunk}
+}
@@ -0,0 +1,243 @@
+// Package otelbedrock instruments the Amazon Bedrock Runtime client from
+// github.com/aws/aws-sdk-go-v2/service/bedrockruntime.
+//
+// Register the middleware on the client's API options and every InvokeModel,
+// InvokeModelWithResponseStream, Converse and ConverseStream call is logged
+// as an LLM span through the Traceloop SDK:
+//
+//	client := bedrockruntime.NewFromConfig(cfg, func(o *bedrockruntime.Options) {
+//		o.APIOptions = append(o.APIOptions, otelbedrock.Middleware(traceloop))
+//	})
+//
+// InvokeModel bodies are decoded according to the model family encoded in
+// the model ID: Anthropic Claude, Amazon Titan, Meta Llama, Mistral and
+// Cohere. Other models are still traced, with token usage only. Calls to the
+// Titan and Cohere embedding models are logged as embedding spans.
+package otelbedrock
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	sdk "github.com/traceloop/go-openllmetry/traceloop-sdk"
+	"github.com/traceloop/go-openllmetry/traceloop-sdk/llmhttp"
+)
+
+const vendor = "bedrock"
+
+type config struct {
+	workflowAttrs sdk.WorkflowAttributes
+}
+
+type Option func(*config)
+
+// WithWorkflowAttributes sets the workflow name and association properties
+// recorded on every LLM span created by the middleware.
+func WithWorkflowAttributes(attrs sdk.WorkflowAttributes) Option {
+	return func(c *config) {
+		c.workflowAttrs = attrs
+	}
+}
+
+// operation converts the typed input and output of a single Bedrock API call.
+type operation interface {
+	prompt() (sdk.Prompt, error)
+	completion(result interface{}) (sdk.Completion, sdk.Usage, error)
+	// streamDecoder returns nil for operations that do not stream.
+	streamDecoder() streamDecoder
+}
+
+// Middleware returns a Smithy API option that traces the Bedrock Runtime
+// operations of the client it is registered on.
+func Middleware(traceloop *sdk.Traceloop, opts ...Option) func(*middleware.Stack) error {
+	cfg := config{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return func(stack *middleware.Stack) error {
+		if err := stack.Initialize.Add(&tracingMiddleware{traceloop: traceloop, cfg: cfg}, middleware.Before); err != nil {
+			return err
+		}
+
+		// Only the streaming operations decode an event stream response.
+		if _, ok := stack.Deserialize.Get(eventStreamDeserializerID); ok {
+			return stack.Deserialize.Insert(&streamMiddleware{}, eventStreamDeserializerID, middleware.After)
+		}
+
+		return nil
+	}
+}
+
+type tracingMiddleware struct {
+	traceloop *sdk.Traceloop
+	cfg       config
+}
+
+func (*tracingMiddleware) ID() string {
+	return "TraceloopTracing"
+}
+
+func (m *tracingMiddleware) HandleInitialize(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (
+	middleware.InitializeOutput, middleware.Metadata, error,
+) {
+	if input, ok := in.Parameters.(*bedrockruntime.InvokeModelInput); ok && isEmbeddingModel(aws.ToString(input.ModelId)) {
+		return m.handleEmbedding(ctx, in, next, newEmbeddingOperation(input))
+	}
+
+	op := operationFor(in.Parameters)
+	if op == nil {
+		return next.HandleInitialize(ctx, in)
+	}
+
+	prompt, err := op.prompt()
+	if err != nil {
+		fmt.Printf("Failed to parse request body: %v\n", err)
+		return next.HandleInitialize(ctx, in)
+	}
+
+	llmSpan, err := m.traceloop.LogPrompt(ctx, prompt, m.cfg.workflowAttrs)
+	if err != nil {
+		return next.HandleInitialize(ctx, in)
+	}
+
+	var stream *eventStream
+	if decoder := op.streamDecoder(); decoder != nil {
+		stream = &eventStream{
+			decoder:    decoder,
+			completion: llmSpan.NewStream(ctx),
+		}
+		ctx = middleware.WithStackValue(ctx, eventStreamKey{}, stream)
+	}
+
+	out, metadata, err := next.HandleInitialize(ctx, in)
+	if err != nil {
+		if stream != nil {
+			stream.completion.CloseWithError(err)
+		} else {
+			llmSpan.LogError(ctx, err)
+		}
+		return out, metadata, err
+	}
+
+	if stream != nil {
+		// The span is logged by the stream body once the caller drains or
+		// closes the event stream.
+		if !stream.attached {
+			stream.completion.Close()
+		}
+		return out, metadata, err
+	}
+
+	completion, usage, err := op.completion(out.Result)
+	if err != nil {
+		llmhttp.LogParseError(ctx, llmSpan, err)
+		return out, metadata, nil
+	}
+	if completion.Model == "" {
+		completion.Model = prompt.Model
+	}
+	if usage.TotalTokens == 0 {
+		usage = headerUsage(metadata)
+	}
+
+	llmSpan.LogCompletion(ctx, completion, usage)
+	return out, metadata, nil
+}
+
+// handleEmbedding logs an InvokeModel call to an embedding model as an
+// embedding span.
+func (m *tracingMiddleware) handleEmbedding(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler, op embeddingOperation) (
+	middleware.InitializeOutput, middleware.Metadata, error,
+) {
+	prompt, err := op.prompt()
+	if err != nil {
+		fmt.Printf("Failed to parse request body: %v\n", err)
+		return next.HandleInitialize(ctx, in)
+	}
+
+	llmSpan, err := m.traceloop.LogEmbeddingPrompt(ctx, prompt, m.cfg.workflowAttrs)
+	if err != nil {
+		return next.HandleInitialize(ctx, in)
+	}
+
+	out, metadata, err := next.HandleInitialize(ctx, in)
+	if err != nil {
+		llmSpan.LogError(ctx, err)
+		return out, metadata, err
+	}
+
+	embeddings, usage, err := op.embeddings(out.Result)
+	if err != nil {
+		llmhttp.LogParseError(ctx, llmSpan, err)
+		return out, metadata, nil
+	}
+	if usage.TotalTokens == 0 {
+		usage = headerUsage(metadata)
+	}
+
+	llmSpan.LogEmbeddings(ctx, embeddings, usage)
+	return out, metadata, nil
+}
+
+func operationFor(params interface{}) operation {
+	switch input := params.(type) {
+	case *bedrockruntime.InvokeModelInput:
+		return newInvokeOperation(input.ModelId, input.Body, false)
+	case *bedrockruntime.InvokeModelWithResponseStreamInput:
+		return newInvokeOperation(input.ModelId, input.Body, true)
+	case *bedrockruntime.ConverseInput:
+		return converseOperation{
+			modelID:         input.ModelId,
+			system:          input.System,
+			messages:        input.Messages,
+			inferenceConfig: input.InferenceConfig,
+			toolConfig:      input.ToolConfig,
+		}
+	case *bedrockruntime.ConverseStreamInput:
+		return converseOperation{
+			modelID:         input.ModelId,
+			system:          input.System,
+			messages:        input.Messages,
+			inferenceConfig: input.InferenceConfig,
+			toolConfig:      input.ToolConfig,
+			stream:          true,
+		}
+	default:
+		return nil
+	}
+}
+
+// headerUsage reads the token counts that Bedrock returns in the response
+// headers of InvokeModel, whatever the model family.
+func headerUsage(metadata middleware.Metadata) sdk.Usage {
+	resp, ok := awsmiddleware.GetRawResponse(metadata).(*smithyhttp.Response)
+	if !ok {
+		return sdk.Usage{}
+	}
+
+	inputTokens, _ := strconv.Atoi(resp.Header.Get("X-Amzn-Bedrock-Input-Token-Count"))
+	outputTokens, _ := strconv.Atoi(resp.Header.Get("X-Amzn-Bedrock-Output-Token-Count"))
+	return tokenUsage(inputTokens, outputTokens, 0, 0)
+}
+
+// tokenUsage builds the usage of a call from Bedrock's token counts, where
+// the input count excludes the tokens read from or written to the prompt
+// cache.
+func tokenUsage(inputTokens, outputTokens, cacheReadTokens, cacheWriteTokens int) sdk.Usage {
+	promptTokens := inputTokens + cacheReadTokens + cacheWriteTokens
+
+	return sdk.Usage{
+		TotalTokens:              promptTokens + outputTokens,
+		CompletionTokens:         outputTokens,
+		PromptTokens:             promptTokens,
+		CacheCreationInputTokens: cacheWriteTokens,
+		CacheReadInputTokens:     cacheReadTokens,
+	}
+}
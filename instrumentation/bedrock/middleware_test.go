@@ -0,0 +1,444 @@
+package otelbedrock
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/document"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	smithyendpoints "github.com/aws/smithy-go/endpoints"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/traceloop/go-openllmetry/traceloop-sdk/tracelooptest"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// fakeEndpointResolver sends every request to the local test server.
+type fakeEndpointResolver struct {
+	url string
+}
+
+func (r fakeEndpointResolver) ResolveEndpoint(ctx context.Context, params bedrockruntime.EndpointParameters) (smithyendpoints.Endpoint, error) {
+	uri, err := url.Parse(r.url)
+	if err != nil {
+		return smithyendpoints.Endpoint{}, err
+	}
+
+	return smithyendpoints.Endpoint{URI: *uri}, nil
+}
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) (*bedrockruntime.Client, *tracetest.InMemoryExporter) {
+	t.Helper()
+
+	server, tl, exporter := tracelooptest.NewServer(t, handler)
+
+	client := bedrockruntime.New(bedrockruntime.Options{
+		Region:             "us-east-1",
+		Credentials:        credentials.NewStaticCredentialsProvider("test-key", "test-secret", ""),
+		EndpointResolverV2: fakeEndpointResolver{url: server.URL},
+		Retryer:            aws.NopRetryer{},
+		APIOptions:         []func(*middleware.Stack) error{Middleware(tl)},
+	})
+
+	return client, exporter
+}
+
+func writeEvent(t *testing.T, w io.Writer, eventType string, payload []byte) {
+	t.Helper()
+
+	message := eventstream.Message{Payload: payload}
+	message.Headers.Set(":message-type", eventstream.StringValue("event"))
+	message.Headers.Set(":event-type", eventstream.StringValue(eventType))
+	message.Headers.Set(":content-type", eventstream.StringValue("application/json"))
+	if err := eventstream.NewEncoder().Encode(w, message); err != nil {
+		t.Fatalf("Failed to encode event: %v", err)
+	}
+}
+
+func writeChunk(t *testing.T, w io.Writer, chunk string) {
+	t.Helper()
+
+	payload, _ := json.Marshal(map[string]string{"bytes": base64.StdEncoding.EncodeToString([]byte(chunk))})
+	writeEvent(t, w, "chunk", payload)
+}
+
+func TestInvokeModelAnthropic(t *testing.T) {
+	client, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/model/anthropic.claude-3-haiku-20240307-v1:0/invoke" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{
+			"id": "msg_1",
+			"type": "message",
+			"role": "assistant",
+			"model": "claude-3-haiku-20240307",
+			"content": [{"type": "tool_use", "id": "toolu_1", "name": "get_weather", "input": {"location": "Paris"}}],
+			"stop_reason": "tool_use",
+			"usage": {"input_tokens": 20, "output_tokens": 9}
+		}`)
+	})
+
+	_, err := client.InvokeModel(context.Background(), &bedrockruntime.InvokeModelInput{
+		ModelId: aws.String("anthropic.claude-3-haiku-20240307-v1:0"),
+		Body: []byte(`{
+			"anthropic_version": "bedrock-2023-05-31",
+			"max_tokens": 512,
+			"temperature": 0.2,
+			"system": "You are a weather bot.",
+			"messages": [{"role": "user", "content": [{"type": "text", "text": "Weather in Paris?"}]}],
+			"tools": [{"name": "get_weather", "description": "Get the current weather", "input_schema": {"type": "object"}}]
+		}`),
+	})
+	if err != nil {
+		t.Fatalf("InvokeModel failed: %v", err)
+	}
+
+	tracelooptest.AssertAttributes(t, tracelooptest.SpanAttributes(t, exporter), map[string]interface{}{
+		"llm.vendor":                               "bedrock",
+		"llm.request.type":                         "chat",
+		"llm.request.model":                        "anthropic.claude-3-haiku-20240307-v1:0",
+		"llm.response.id":                          "msg_1",
+		"llm.response.model":                       "claude-3-haiku-20240307",
+		"llm.prompts.0.role":                       "system",
+		"llm.prompts.0.content":                    "You are a weather bot.",
+		"llm.prompts.1.content":                    "Weather in Paris?",
+		"llm.request.functions.0.name":             "get_weather",
+		"llm.completions.0.finish_reason":          "tool_use",
+		"llm.completions.0.tool_calls.0.id":        "toolu_1",
+		"llm.completions.0.tool_calls.0.arguments": `{"location": "Paris"}`,
+		"llm.usage.prompt_tokens":                  int64(20),
+		"llm.usage.completion_tokens":              int64(9),
+	})
+}
+
+func TestInvokeModelUsageFromHeaders(t *testing.T) {
+	client, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Amzn-Bedrock-Input-Token-Count", "11")
+		w.Header().Set("X-Amzn-Bedrock-Output-Token-Count", "4")
+		io.WriteString(w, `{"outputs": [{"text": "Bonjour!", "stop_reason": "stop"}]}`)
+	})
+
+	_, err := client.InvokeModel(context.Background(), &bedrockruntime.InvokeModelInput{
+		ModelId: aws.String("mistral.mistral-7b-instruct-v0:2"),
+		Body:    []byte(`{"prompt": "<s>[INST] Say hello in French [/INST]", "max_tokens": 50}`),
+	})
+	if err != nil {
+		t.Fatalf("InvokeModel failed: %v", err)
+	}
+
+	tracelooptest.AssertAttributes(t, tracelooptest.SpanAttributes(t, exporter), map[string]interface{}{
+		"llm.request.type":                "completion",
+		"llm.prompts.0.content":           "<s>[INST] Say hello in French [/INST]",
+		"llm.completions.0.content":       "Bonjour!",
+		"llm.completions.0.finish_reason": "stop",
+		"llm.usage.prompt_tokens":         int64(11),
+		"llm.usage.completion_tokens":     int64(4),
+		"llm.usage.total_tokens":          int64(15),
+	})
+}
+
+func TestInvokeModelEmbedding(t *testing.T) {
+	client, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"embedding": [0.1, 0.2, 0.3, 0.4], "inputTextTokenCount": 5}`)
+	})
+
+	_, err := client.InvokeModel(context.Background(), &bedrockruntime.InvokeModelInput{
+		ModelId: aws.String("amazon.titan-embed-text-v2:0"),
+		Body:    []byte(`{"inputText": "Weather in Paris", "dimensions": 256}`),
+	})
+	if err != nil {
+		t.Fatalf("InvokeModel failed: %v", err)
+	}
+
+	tracelooptest.AssertAttributes(t, tracelooptest.SpanAttributes(t, exporter), map[string]interface{}{
+		"llm.request.type":                  "embedding",
+		"llm.request.model":                 "amazon.titan-embed-text-v2:0",
+		"llm.prompts.0.content":             "Weather in Paris",
+		"llm.request.embedding.input_count": int64(1),
+		"llm.request.embedding.dimensions":  int64(256),
+		"llm.response.embedding.count":      int64(1),
+		"llm.response.embedding.dimensions": int64(4),
+		"llm.usage.prompt_tokens":           int64(5),
+	})
+}
+
+func TestInvokeModelCohereEmbedding(t *testing.T) {
+	client, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Amzn-Bedrock-Input-Token-Count", "6")
+		io.WriteString(w, `{"id": "emb_1", "embeddings": {"float": [[0.1, 0.2], [0.3, 0.4]]}, "response_type": "embeddings_by_type"}`)
+	})
+
+	_, err := client.InvokeModel(context.Background(), &bedrockruntime.InvokeModelInput{
+		ModelId: aws.String("cohere.embed-english-v3"),
+		Body:    []byte(`{"texts": ["first", "second"], "input_type": "search_document", "embedding_types": ["float"]}`),
+	})
+	if err != nil {
+		t.Fatalf("InvokeModel failed: %v", err)
+	}
+
+	tracelooptest.AssertAttributes(t, tracelooptest.SpanAttributes(t, exporter), map[string]interface{}{
+		"llm.request.type":                      "embedding",
+		"llm.prompts.1.content":                 "second",
+		"llm.request.embedding.input_count":     int64(2),
+		"llm.request.embedding.encoding_format": "float",
+		"llm.response.embedding.count":          int64(2),
+		"llm.response.embedding.dimensions":     int64(2),
+		"llm.usage.prompt_tokens":               int64(6),
+	})
+}
+
+func TestInvokeModelWithResponseStream(t *testing.T) {
+	client, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.amazon.eventstream")
+		writeChunk(t, w, `{"outputText": "Hello", "index": 0, "inputTextTokenCount": 5}`)
+		writeChunk(t, w, `{"outputText": " world", "index": 0, "totalOutputTextTokenCount": 2, "completionReason": "FINISH"}`)
+	})
+
+	out, err := client.InvokeModelWithResponseStream(context.Background(), &bedrockruntime.InvokeModelWithResponseStreamInput{
+		ModelId: aws.String("amazon.titan-text-express-v1"),
+		Body:    []byte(`{"inputText": "Say hello", "textGenerationConfig": {"temperature": 0.5}}`),
+	})
+	if err != nil {
+		t.Fatalf("InvokeModelWithResponseStream failed: %v", err)
+	}
+
+	stream := out.GetStream()
+	for range stream.Events() {
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+
+	tracelooptest.AssertAttributes(t, tracelooptest.SpanAttributes(t, exporter), map[string]interface{}{
+		"llm.request.model":               "amazon.titan-text-express-v1",
+		"llm.prompts.0.content":           "Say hello",
+		"llm.completions.0.content":       "Hello world",
+		"llm.completions.0.finish_reason": "FINISH",
+		"llm.usage.total_tokens":          int64(7),
+		"llm.is_streaming":                true,
+	})
+}
+
+func TestInvokeModelWithResponseStreamInvocationMetrics(t *testing.T) {
+	client, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.amazon.eventstream")
+		writeChunk(t, w, `{"is_finished": false, "text": "Hi"}`)
+		writeChunk(t, w, `{"is_finished": true, "finish_reason": "COMPLETE", "amazon-bedrock-invocationMetrics": {"inputTokenCount": 3, "outputTokenCount": 1}}`)
+	})
+
+	out, err := client.InvokeModelWithResponseStream(context.Background(), &bedrockruntime.InvokeModelWithResponseStreamInput{
+		ModelId: aws.String("cohere.command-text-v14"),
+		Body:    []byte(`{"prompt": "Say hi", "stream": true}`),
+	})
+	if err != nil {
+		t.Fatalf("InvokeModelWithResponseStream failed: %v", err)
+	}
+
+	stream := out.GetStream()
+	for range stream.Events() {
+	}
+	stream.Close()
+
+	tracelooptest.AssertAttributes(t, tracelooptest.SpanAttributes(t, exporter), map[string]interface{}{
+		"llm.completions.0.content":       "Hi",
+		"llm.completions.0.finish_reason": "COMPLETE",
+		"llm.usage.prompt_tokens":         int64(3),
+		"llm.usage.completion_tokens":     int64(1),
+	})
+}
+
+func TestConverse(t *testing.T) {
+	client, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/model/us.amazon.nova-lite-v1:0/converse" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{
+			"output": {"message": {"role": "assistant", "content": [
+				{"text": "Let me check."},
+				{"toolUse": {"toolUseId": "tool_1", "name": "get_weather", "input": {"location": "Paris"}}}
+			]}},
+			"stopReason": "tool_use",
+			"usage": {"inputTokens": 30, "outputTokens": 12, "totalTokens": 42, "cacheReadInputTokens": 8},
+			"metrics": {"latencyMs": 120}
+		}`)
+	})
+
+	_, err := client.Converse(context.Background(), &bedrockruntime.ConverseInput{
+		ModelId: aws.String("us.amazon.nova-lite-v1:0"),
+		System:  []types.SystemContentBlock{&types.SystemContentBlockMemberText{Value: "You are a weather bot."}},
+		Messages: []types.Message{{
+			Role: types.ConversationRoleUser,
+			Content: []types.ContentBlock{
+				&types.ContentBlockMemberText{Value: "Weather in Paris?"},
+				&types.ContentBlockMemberImage{Value: types.ImageBlock{
+					Format: types.ImageFormatPng,
+					Source: &types.ImageSourceMemberBytes{Value: []byte("not really a png")},
+				}},
+			},
+		}},
+		InferenceConfig: &types.InferenceConfiguration{Temperature: aws.Float32(0.3), MaxTokens: aws.Int32(300)},
+		ToolConfig: &types.ToolConfiguration{
+			Tools: []types.Tool{
+				&types.ToolMemberToolSpec{Value: types.ToolSpecification{
+					Name:        aws.String("get_weather"),
+					Description: aws.String("Get the current weather"),
+					InputSchema: &types.ToolInputSchemaMemberJson{Value: document.NewLazyDocument(map[string]interface{}{"type": "object"})},
+				}},
+			},
+			ToolChoice: &types.ToolChoiceMemberAny{},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Converse failed: %v", err)
+	}
+
+	tracelooptest.AssertAttributes(t, tracelooptest.SpanAttributes(t, exporter), map[string]interface{}{
+		"llm.request.type":                         "chat",
+		"llm.request.model":                        "us.amazon.nova-lite-v1:0",
+		"llm.prompts.0.role":                       "system",
+		"llm.prompts.1.role":                       "user",
+		"llm.prompts.1.content":                    "Weather in Paris?",
+		"llm.prompts.1.content_parts.1.type":       "image",
+		"llm.prompts.1.content_parts.1.mime_type":  "image/png",
+		"llm.prompts.1.content_parts.1.size":       int64(16),
+		"llm.request.functions.0.name":             "get_weather",
+		"llm.request.functions.0.parameters":       `{"type":"object"}`,
+		"llm.request.max_tokens":                   int64(300),
+		"llm.request.tool_choice":                  "any",
+		"llm.completions.0.role":                   "assistant",
+		"llm.completions.0.content":                "Let me check.",
+		"llm.completions.0.finish_reason":          "tool_use",
+		"llm.completions.0.tool_calls.0.name":      "get_weather",
+		"llm.completions.0.tool_calls.0.arguments": `{"location":"Paris"}`,
+		"llm.usage.prompt_tokens":                  int64(38),
+		"llm.usage.completion_tokens":              int64(12),
+		"llm.usage.cache_read_input_tokens":        int64(8),
+	})
+}
+
+func TestConverseStream(t *testing.T) {
+	client, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.amazon.eventstream")
+		writeEvent(t, w, "messageStart", []byte(`{"role": "assistant"}`))
+		writeEvent(t, w, "contentBlockDelta", []byte(`{"contentBlockIndex": 0, "delta": {"text": "Sunny"}}`))
+		writeEvent(t, w, "contentBlockDelta", []byte(`{"contentBlockIndex": 0, "delta": {"text": " today"}}`))
+		writeEvent(t, w, "contentBlockStop", []byte(`{"contentBlockIndex": 0}`))
+		writeEvent(t, w, "contentBlockStart", []byte(`{"contentBlockIndex": 1, "start": {"toolUse": {"toolUseId": "tool_1", "name": "get_weather"}}}`))
+		writeEvent(t, w, "contentBlockDelta", []byte(`{"contentBlockIndex": 1, "delta": {"toolUse": {"input": "{\"location\":"}}}`))
+		writeEvent(t, w, "contentBlockDelta", []byte(`{"contentBlockIndex": 1, "delta": {"toolUse": {"input": "\"Paris\"}"}}}`))
+		writeEvent(t, w, "contentBlockStop", []byte(`{"contentBlockIndex": 1}`))
+		writeEvent(t, w, "messageStop", []byte(`{"stopReason": "tool_use"}`))
+		writeEvent(t, w, "metadata", []byte(`{"usage": {"inputTokens": 6, "outputTokens": 2, "totalTokens": 8}, "metrics": {"latencyMs": 80}}`))
+	})
+
+	out, err := client.ConverseStream(context.Background(), &bedrockruntime.ConverseStreamInput{
+		ModelId: aws.String("anthropic.claude-3-haiku-20240307-v1:0"),
+		Messages: []types.Message{{
+			Role:    types.ConversationRoleUser,
+			Content: []types.ContentBlock{&types.ContentBlockMemberText{Value: "Weather?"}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("ConverseStream failed: %v", err)
+	}
+
+	stream := out.GetStream()
+	for range stream.Events() {
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+
+	tracelooptest.AssertAttributes(t, tracelooptest.SpanAttributes(t, exporter), map[string]interface{}{
+		"llm.response.model":                       "anthropic.claude-3-haiku-20240307-v1:0",
+		"llm.completions.0.content":                "Sunny today",
+		"llm.completions.0.finish_reason":          "tool_use",
+		"llm.completions.0.tool_calls.0.id":        "tool_1",
+		"llm.completions.0.tool_calls.0.arguments": `{"location":"Paris"}`,
+		"llm.usage.total_tokens":                   int64(8),
+		"llm.is_streaming":                         true,
+	})
+}
+
+func TestInvokeModelError(t *testing.T) {
+	client, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Amzn-ErrorType", "ThrottlingException")
+		w.WriteHeader(http.StatusTooManyRequests)
+		io.WriteString(w, `{"message": "Too many requests"}`)
+	})
+
+	_, err := client.InvokeModel(context.Background(), &bedrockruntime.InvokeModelInput{
+		ModelId: aws.String("meta.llama3-8b-instruct-v1:0"),
+		Body:    []byte(`{"prompt": "Hi"}`),
+	})
+	if err == nil {
+		t.Fatal("Expected InvokeModel to fail")
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected the failed call's span to be ended, got %d spans", len(spans))
+	}
+	if spans[0].Status.Code != codes.Error {
+		t.Errorf("Expected the span status to be an error, got %v", spans[0].Status)
+	}
+	tracelooptest.AssertAttributes(t, tracelooptest.SpanAttributes(t, exporter), map[string]interface{}{
+		"llm.error.type": "rate_limit",
+	})
+}
+
+func TestInvokeModelWithResponseStreamException(t *testing.T) {
+	client, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.amazon.eventstream")
+		writeChunk(t, w, `{"outputText": "Hello", "index": 0, "inputTextTokenCount": 5}`)
+
+		message := eventstream.Message{Payload: []byte(`{"message": "Input is too long for requested model."}`)}
+		message.Headers.Set(":message-type", eventstream.StringValue("exception"))
+		message.Headers.Set(":exception-type", eventstream.StringValue("validationException"))
+		message.Headers.Set(":content-type", eventstream.StringValue("application/json"))
+		if err := eventstream.NewEncoder().Encode(w, message); err != nil {
+			t.Fatalf("Failed to encode exception: %v", err)
+		}
+	})
+
+	out, err := client.InvokeModelWithResponseStream(context.Background(), &bedrockruntime.InvokeModelWithResponseStreamInput{
+		ModelId: aws.String("amazon.titan-text-express-v1"),
+		Body:    []byte(`{"inputText": "Say hello"}`),
+	})
+	if err != nil {
+		t.Fatalf("InvokeModelWithResponseStream failed: %v", err)
+	}
+
+	stream := out.GetStream()
+	for range stream.Events() {
+	}
+	if err := stream.Close(); err == nil {
+		t.Fatal("Expected the stream to fail")
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected the failed stream's span to be ended, got %d spans", len(spans))
+	}
+	if spans[0].Status.Code != codes.Error {
+		t.Errorf("Expected the span status to be an error, got %v", spans[0].Status)
+	}
+	tracelooptest.AssertAttributes(t, tracelooptest.SpanAttributes(t, exporter), map[string]interface{}{
+		"llm.error.type": "context_length_exceeded",
+	})
+}
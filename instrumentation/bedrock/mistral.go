@@ -0,0 +1,120 @@
+package otelbedrock
+
+import (
+	"encoding/json"
+
+	sdk "github.com/traceloop/go-openllmetry/traceloop-sdk"
+)
+
+// mistralCodec decodes the Mistral bodies: the text completion format of
+// most models, and the chat format accepted by Mistral Large.
+type mistralCodec struct{}
+
+type mistralRequest struct {
+	Prompt   string `json:"prompt"`
+	Messages []struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	} `json:"messages"`
+	Temperature *float32 `json:"temperature"`
+	TopP        *float32 `json:"top_p"`
+	Stop        []string `json:"stop"`
+	MaxTokens   int      `json:"max_tokens"`
+}
+
+type mistralOutput struct {
+	Index   int    `json:"index"`
+	Text    string `json:"text"`
+	Message struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	} `json:"message"`
+	StopReason string `json:"stop_reason"`
+}
+
+// mistralResponse holds either text completion outputs or chat choices.
+type mistralResponse struct {
+	Outputs []mistralOutput `json:"outputs"`
+	Choices []mistralOutput `json:"choices"`
+}
+
+func (mistralCodec) prompt(body []byte) (sdk.Prompt, error) {
+	var request mistralRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		return sdk.Prompt{}, err
+	}
+
+	prompt := sdk.Prompt{
+		Mode:        "completion",
+		Temperature: request.Temperature,
+		TopP:        request.TopP,
+		Stop:        request.Stop,
+		MaxTokens:   request.MaxTokens,
+		Messages:    textMessages(request.Prompt),
+	}
+
+	if len(request.Messages) > 0 {
+		prompt.Mode = "chat"
+		prompt.Messages = nil
+		for i, m := range request.Messages {
+			prompt.Messages = append(prompt.Messages, sdk.Message{
+				Index:   i,
+				Role:    m.Role,
+				Content: m.Content,
+			})
+		}
+	}
+
+	return prompt, nil
+}
+
+func (mistralCodec) completion(body []byte) (sdk.Completion, sdk.Usage, error) {
+	var response mistralResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return sdk.Completion{}, sdk.Usage{}, err
+	}
+
+	// Mistral bodies carry no token counts; they come from the response
+	// headers instead.
+	return response.toCompletion(), sdk.Usage{}, nil
+}
+
+func (mistralCodec) bodyDecoder() bodyDecoder {
+	return mistralDecoder{}
+}
+
+func (response mistralResponse) toCompletion() sdk.Completion {
+	var texts, finishReasons []string
+	for _, output := range response.Outputs {
+		texts = append(texts, output.Text)
+		finishReasons = append(finishReasons, output.StopReason)
+	}
+	for _, choice := range response.Choices {
+		texts = append(texts, choice.Message.Content)
+		finishReasons = append(finishReasons, choice.StopReason)
+	}
+
+	return textCompletion(texts, finishReasons)
+}
+
+// mistralDecoder converts the streamed text of every output or choice,
+// which is identified by its index.
+type mistralDecoder struct{}
+
+func (mistralDecoder) chunks(body []byte) []sdk.StreamChunk {
+	var event mistralResponse
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil
+	}
+
+	var chunks []sdk.StreamChunk
+	for _, output := range append(event.Outputs, event.Choices...) {
+		chunks = append(chunks, sdk.StreamChunk{
+			Index:        output.Index,
+			Content:      output.Text + output.Message.Content,
+			FinishReason: output.StopReason,
+		})
+	}
+
+	return chunks
+}
@@ -0,0 +1,181 @@
+package otelbedrock
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	sdk "github.com/traceloop/go-openllmetry/traceloop-sdk"
+)
+
+// eventStreamDeserializerID is the deserialize middleware that the Bedrock
+// client registers for operations returning an event stream.
+const eventStreamDeserializerID = "OperationEventStreamDeserializer"
+
+// minMessageLength is the size of an event stream message without headers
+// or payload: the prelude, its checksum and the message checksum.
+const minMessageLength = 16
+
+// streamDecoder converts the events of an event stream response into
+// completion chunks.
+type streamDecoder interface {
+	chunks(eventType string, payload []byte) []sdk.StreamChunk
+}
+
+type eventStreamKey struct{}
+
+// eventStream carries a streaming call from the initialize step, where its
+// span is started, to the deserialize step, where the response body becomes
+// available.
+type eventStream struct {
+	decoder    streamDecoder
+	completion *sdk.CompletionStream
+	attached   bool
+}
+
+// streamMiddleware runs just inside the client's event stream deserializer,
+// so the response body it wraps is the one the SDK reads events from.
+type streamMiddleware struct{}
+
+func (*streamMiddleware) ID() string {
+	return "TraceloopEventStream"
+}
+
+func (*streamMiddleware) HandleDeserialize(ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler) (
+	middleware.DeserializeOutput, middleware.Metadata, error,
+) {
+	out, metadata, err := next.HandleDeserialize(ctx, in)
+	if err != nil {
+		return out, metadata, err
+	}
+
+	stream, ok := middleware.GetStackValue(ctx, eventStreamKey{}).(*eventStream)
+	if !ok {
+		return out, metadata, err
+	}
+
+	if resp, ok := out.RawResponse.(*smithyhttp.Response); ok && resp.Body != nil {
+		resp.Body = newStreamBody(resp.Body, stream.decoder, stream.completion)
+		stream.attached = true
+	}
+
+	return out, metadata, err
+}
+
+// streamBody passes an event stream through to the SDK while adding the
+// chunks of each event to a completion stream, which logs the completion
+// once the body is drained or closed. The stream is closed with the
+// exception sent in the stream or the error that interrupted it, if any.
+type streamBody struct {
+	io.ReadCloser
+	decoder      streamDecoder
+	completion   *sdk.CompletionStream
+	eventDecoder *eventstream.Decoder
+	pending      []byte
+	err          error
+}
+
+func newStreamBody(body io.ReadCloser, decoder streamDecoder, completion *sdk.CompletionStream) *streamBody {
+	return &streamBody{
+		ReadCloser:   body,
+		decoder:      decoder,
+		completion:   completion,
+		eventDecoder: eventstream.NewDecoder(),
+	}
+}
+
+func (body *streamBody) Read(p []byte) (int, error) {
+	n, err := body.ReadCloser.Read(p)
+	if n > 0 {
+		body.feed(p[:n])
+	}
+	if err != nil {
+		if !errors.Is(err, io.EOF) && body.err == nil {
+			body.err = err
+		}
+		body.finish()
+	}
+
+	return n, err
+}
+
+func (body *streamBody) Close() error {
+	body.finish()
+	return body.ReadCloser.Close()
+}
+
+func (body *streamBody) feed(chunk []byte) {
+	body.pending = append(body.pending, chunk...)
+
+	// Every message starts with its total length, so complete messages can
+	// be cut from the buffer without waiting for the rest of the stream.
+	for len(body.pending) >= 4 {
+		length := int(binary.BigEndian.Uint32(body.pending))
+		if length < minMessageLength {
+			// The stream is corrupt; the SDK reports the error itself.
+			body.pending = nil
+			return
+		}
+		if len(body.pending) < length {
+			return
+		}
+
+		raw := body.pending[:length]
+		body.pending = body.pending[length:]
+
+		message, err := body.eventDecoder.Decode(bytes.NewReader(raw), nil)
+		if err != nil {
+			continue
+		}
+
+		switch headerValue(message, ":message-type") {
+		case "event":
+			for _, chunk := range body.decoder.chunks(headerValue(message, ":event-type"), message.Payload) {
+				body.completion.AddChunk(chunk)
+			}
+		case "exception":
+			if body.err == nil {
+				body.err = exceptionError(message)
+			}
+		}
+	}
+}
+
+func (body *streamBody) finish() {
+	if body.err != nil {
+		body.completion.CloseWithError(body.err)
+		return
+	}
+
+	body.completion.Close()
+}
+
+// exceptionError converts an exception sent in the stream, such as a
+// throttlingException, into the API error the SDK returns for it.
+func exceptionError(message eventstream.Message) error {
+	var payload struct {
+		Message string `json:"message"`
+	}
+	json.Unmarshal(message.Payload, &payload)
+
+	return &smithy.GenericAPIError{
+		Code:    headerValue(message, ":exception-type"),
+		Message: payload.Message,
+	}
+}
+
+func headerValue(message eventstream.Message, name string) string {
+	value := message.Headers.Get(name)
+	if value == nil {
+		return ""
+	}
+
+	return value.String()
+}
@@ -0,0 +1,100 @@
+package otelbedrock
+
+import (
+	"encoding/json"
+
+	sdk "github.com/traceloop/go-openllmetry/traceloop-sdk"
+)
+
+// titanCodec decodes the Amazon Titan Text bodies.
+type titanCodec struct{}
+
+type titanRequest struct {
+	InputText            string `json:"inputText"`
+	TextGenerationConfig struct {
+		Temperature   *float32 `json:"temperature"`
+		TopP          *float32 `json:"topP"`
+		StopSequences []string `json:"stopSequences"`
+		MaxTokenCount int      `json:"maxTokenCount"`
+	} `json:"textGenerationConfig"`
+}
+
+type titanResult struct {
+	TokenCount       int    `json:"tokenCount"`
+	OutputText       string `json:"outputText"`
+	CompletionReason string `json:"completionReason"`
+}
+
+type titanResponse struct {
+	InputTextTokenCount int           `json:"inputTextTokenCount"`
+	Results             []titanResult `json:"results"`
+}
+
+func (titanCodec) prompt(body []byte) (sdk.Prompt, error) {
+	var request titanRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		return sdk.Prompt{}, err
+	}
+
+	return sdk.Prompt{
+		Mode:        "completion",
+		Temperature: request.TextGenerationConfig.Temperature,
+		TopP:        request.TextGenerationConfig.TopP,
+		Stop:        request.TextGenerationConfig.StopSequences,
+		MaxTokens:   request.TextGenerationConfig.MaxTokenCount,
+		Messages:    textMessages(request.InputText),
+	}, nil
+}
+
+func (titanCodec) completion(body []byte) (sdk.Completion, sdk.Usage, error) {
+	var response titanResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return sdk.Completion{}, sdk.Usage{}, err
+	}
+
+	var texts, finishReasons []string
+	var outputTokens int
+	for _, result := range response.Results {
+		texts = append(texts, result.OutputText)
+		finishReasons = append(finishReasons, result.CompletionReason)
+		outputTokens += result.TokenCount
+	}
+
+	return textCompletion(texts, finishReasons), tokenUsage(response.InputTextTokenCount, outputTokens, 0, 0), nil
+}
+
+func (titanCodec) bodyDecoder() bodyDecoder {
+	return &titanDecoder{}
+}
+
+type titanDecoder struct {
+	inputTokens  int
+	outputTokens int
+}
+
+func (decoder *titanDecoder) chunks(body []byte) []sdk.StreamChunk {
+	var event struct {
+		OutputText                string `json:"outputText"`
+		CompletionReason          string `json:"completionReason"`
+		InputTextTokenCount       int    `json:"inputTextTokenCount"`
+		TotalOutputTextTokenCount int    `json:"totalOutputTextTokenCount"`
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil
+	}
+
+	if event.InputTextTokenCount > 0 {
+		decoder.inputTokens = event.InputTextTokenCount
+	}
+	if event.TotalOutputTextTokenCount > 0 {
+		decoder.outputTokens = event.TotalOutputTextTokenCount
+	}
+
+	chunk := sdk.StreamChunk{Content: event.OutputText, FinishReason: event.CompletionReason}
+	if event.InputTextTokenCount > 0 || event.TotalOutputTextTokenCount > 0 {
+		usage := tokenUsage(decoder.inputTokens, decoder.outputTokens, 0, 0)
+		chunk.Usage = &usage
+	}
+
+	return []sdk.StreamChunk{chunk}
+}
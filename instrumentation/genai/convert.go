@@ -0,0 +1,350 @@
+package otelgenai
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	semconvai "github.com/traceloop/go-openllmetry/semconv-ai"
+	sdk "github.com/traceloop/go-openllmetry/traceloop-sdk"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/genai"
+)
+
+func messages(systemInstruction *genai.Content, contents []*genai.Content) []sdk.Message {
+	var result []sdk.Message
+	if systemInstruction != nil {
+		result = append(result, message(0, "system", systemInstruction))
+	}
+
+	for _, content := range contents {
+		if content == nil {
+			continue
+		}
+		result = append(result, message(len(result), content.Role, content))
+	}
+
+	return result
+}
+
+func message(index int, role string, content *genai.Content) sdk.Message {
+	var texts []string
+	var parts []sdk.ContentPart
+	var toolCalls []sdk.ToolCall
+	var toolCallID string
+	hasBinary := false
+	for _, part := range content.Parts {
+		switch {
+		case part == nil || part.Thought:
+		case part.Text != "":
+			texts = append(texts, part.Text)
+			parts = append(parts, sdk.ContentPart{Type: sdk.ContentPartText, Text: part.Text})
+		case part.InlineData != nil:
+			hasBinary = true
+			parts = append(parts, sdk.ContentPart{
+				Type:     contentPartType(part.InlineData.MIMEType),
+				Data:     part.InlineData.Data,
+				MimeType: part.InlineData.MIMEType,
+				Filename: part.InlineData.DisplayName,
+			})
+		case part.FileData != nil:
+			hasBinary = true
+			parts = append(parts, sdk.ContentPart{
+				Type:     contentPartType(part.FileData.MIMEType),
+				URL:      part.FileData.FileURI,
+				MimeType: part.FileData.MIMEType,
+				Filename: part.FileData.DisplayName,
+			})
+		case part.FunctionCall != nil:
+			args, err := json.Marshal(part.FunctionCall.Args)
+			if err != nil {
+				fmt.Printf("Failed to marshal function call args for %s: %v\n", part.FunctionCall.Name, err)
+			}
+			toolCalls = append(toolCalls, sdk.ToolCall{
+				ID:   part.FunctionCall.ID,
+				Type: "function",
+				Function: sdk.ToolCallFunction{
+					Name:      part.FunctionCall.Name,
+					Arguments: string(args),
+				},
+			})
+		case part.FunctionResponse != nil:
+			response, err := json.Marshal(part.FunctionResponse.Response)
+			if err != nil {
+				fmt.Printf("Failed to marshal function response for %s: %v\n", part.FunctionResponse.Name, err)
+			}
+			texts = append(texts, string(response))
+			if toolCallID == "" {
+				toolCallID = part.FunctionResponse.ID
+			}
+		}
+	}
+
+	if role == "" {
+		role = genai.RoleUser
+	}
+	// Text-only content is already recorded as the message's content.
+	if !hasBinary {
+		parts = nil
+	}
+
+	return sdk.Message{
+		Index:        index,
+		Role:         role,
+		Content:      strings.Join(texts, "\n"),
+		ContentParts: parts,
+		ToolCalls:    toolCalls,
+		ToolCallID:   toolCallID,
+	}
+}
+
+func contentPartType(mimeType string) string {
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return sdk.ContentPartImage
+	case strings.HasPrefix(mimeType, "audio/"):
+		return sdk.ContentPartAudio
+	default:
+		return sdk.ContentPartFile
+	}
+}
+
+func tools(genaiTools []*genai.Tool) []sdk.Tool {
+	var result []sdk.Tool
+	for _, tool := range genaiTools {
+		if tool == nil {
+			continue
+		}
+		for _, declaration := range tool.FunctionDeclarations {
+			var parameters interface{}
+			if declaration.ParametersJsonSchema != nil {
+				parameters = declaration.ParametersJsonSchema
+			} else if declaration.Parameters != nil {
+				parameters = declaration.Parameters
+			}
+			result = append(result, sdk.Tool{
+				Type: "function",
+				Function: sdk.ToolFunction{
+					Name:        declaration.Name,
+					Description: declaration.Description,
+					Parameters:  parameters,
+				},
+			})
+		}
+	}
+
+	return result
+}
+
+func completion(model string, resp *genai.GenerateContentResponse) sdk.Completion {
+	if resp.ModelVersion != "" {
+		model = resp.ModelVersion
+	}
+
+	var result []sdk.Message
+	for i, candidate := range resp.Candidates {
+		if candidate == nil {
+			continue
+		}
+		index := int(candidate.Index)
+		if index == 0 {
+			index = i
+		}
+
+		content := candidate.Content
+		if content == nil {
+			content = &genai.Content{Role: genai.RoleModel}
+		}
+
+		msg := message(index, content.Role, content)
+		if content.Role == "" {
+			msg.Role = genai.RoleModel
+		}
+		msg.FinishReason = string(candidate.FinishReason)
+		result = append(result, msg)
+	}
+
+	return sdk.Completion{
+		ID:       resp.ResponseID,
+		Model:    model,
+		Messages: result,
+	}
+}
+
+func usage(metadata *genai.GenerateContentResponseUsageMetadata) sdk.Usage {
+	if metadata == nil {
+		return sdk.Usage{}
+	}
+
+	return sdk.Usage{
+		TotalTokens:           int(metadata.TotalTokenCount),
+		CompletionTokens:      int(metadata.CandidatesTokenCount + metadata.ThoughtsTokenCount),
+		PromptTokens:          int(metadata.PromptTokenCount + metadata.ToolUsePromptTokenCount),
+		CacheReadInputTokens:  int(metadata.CachedContentTokenCount),
+		ReasoningTokens:       int(metadata.ThoughtsTokenCount),
+		PromptAudioTokens:     modalityTokens(metadata.PromptTokensDetails, genai.MediaModalityAudio),
+		CompletionAudioTokens: modalityTokens(metadata.CandidatesTokensDetails, genai.MediaModalityAudio),
+		PromptImageTokens:     modalityTokens(metadata.PromptTokensDetails, genai.MediaModalityImage),
+		CompletionImageTokens: modalityTokens(metadata.CandidatesTokensDetails, genai.MediaModalityImage),
+	}
+}
+
+func modalityTokens(details []*genai.ModalityTokenCount, modality genai.MediaModality) int {
+	var tokens int
+	for _, count := range details {
+		if count != nil && count.Modality == modality {
+			tokens += int(count.TokenCount)
+		}
+	}
+
+	return tokens
+}
+
+// safetyAttributes records the safety ratings of every candidate and the
+// prompt feedback, which Gemini returns instead of an error when it blocks
+// the prompt.
+func safetyAttributes(resp *genai.GenerateContentResponse) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	for i, candidate := range resp.Candidates {
+		if candidate == nil {
+			continue
+		}
+		index := int(candidate.Index)
+		if index == 0 {
+			index = i
+		}
+		prefix := fmt.Sprintf("%s.%d.safety_ratings", semconvai.LLMCompletions, index)
+		attrs = append(attrs, safetyRatingAttributes(prefix, candidate.SafetyRatings)...)
+	}
+
+	if resp.PromptFeedback != nil {
+		if resp.PromptFeedback.BlockReason != "" {
+			attrs = append(attrs, semconvai.LLMPromptFeedbackBlockReason.String(string(resp.PromptFeedback.BlockReason)))
+		}
+		attrs = append(attrs, safetyRatingAttributes(string(semconvai.LLMPromptFeedbackSafetyRatings), resp.PromptFeedback.SafetyRatings)...)
+	}
+
+	return attrs
+}
+
+func safetyRatingAttributes(prefix string, ratings []*genai.SafetyRating) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	for i, rating := range ratings {
+		if rating == nil {
+			continue
+		}
+		ratingPrefix := fmt.Sprintf("%s.%d", prefix, i)
+		attrs = append(attrs,
+			attribute.String(ratingPrefix+".category", string(rating.Category)),
+			attribute.String(ratingPrefix+".probability", string(rating.Probability)),
+			attribute.Bool(ratingPrefix+".blocked", rating.Blocked),
+		)
+	}
+
+	return attrs
+}
+
+// streamDecoder converts streamed GenerateContent chunks into completion
+// chunks. The safety ratings and the prompt feedback are kept as they arrive
+// and recorded once the stream ends.
+type streamDecoder struct {
+	safetyRatings  map[int][]*genai.SafetyRating
+	promptFeedback *genai.GenerateContentResponsePromptFeedback
+	// toolCalls counts the function calls of every candidate, which are
+	// streamed whole.
+	toolCalls map[int]int
+}
+
+func newStreamDecoder() *streamDecoder {
+	return &streamDecoder{
+		safetyRatings: make(map[int][]*genai.SafetyRating),
+		toolCalls:     make(map[int]int),
+	}
+}
+
+func (decoder *streamDecoder) chunks(resp *genai.GenerateContentResponse) []sdk.StreamChunk {
+	if resp == nil {
+		return nil
+	}
+
+	if resp.PromptFeedback != nil {
+		decoder.promptFeedback = resp.PromptFeedback
+	}
+
+	var chunkUsage *sdk.Usage
+	if resp.UsageMetadata != nil {
+		u := usage(resp.UsageMetadata)
+		chunkUsage = &u
+	}
+
+	var chunks []sdk.StreamChunk
+	for i, candidate := range resp.Candidates {
+		if candidate == nil {
+			continue
+		}
+		index := int(candidate.Index)
+		if index == 0 {
+			index = i
+		}
+		if len(candidate.SafetyRatings) > 0 {
+			decoder.safetyRatings[index] = candidate.SafetyRatings
+		}
+
+		chunk := sdk.StreamChunk{
+			ID:           resp.ResponseID,
+			Model:        resp.ModelVersion,
+			Index:        index,
+			Role:         genai.RoleModel,
+			FinishReason: string(candidate.FinishReason),
+		}
+		if candidate.Content != nil {
+			msg := message(index, candidate.Content.Role, candidate.Content)
+			if candidate.Content.Role != "" {
+				chunk.Role = msg.Role
+			}
+			chunk.Content = msg.Content
+			for _, toolCall := range msg.ToolCalls {
+				chunk.ToolCalls = append(chunk.ToolCalls, sdk.ToolCallDelta{
+					Index:     decoder.toolCalls[index],
+					ID:        toolCall.ID,
+					Type:      toolCall.Type,
+					Name:      toolCall.Function.Name,
+					Arguments: toolCall.Function.Arguments,
+				})
+				decoder.toolCalls[index]++
+			}
+		}
+		if len(chunks) == 0 {
+			chunk.Usage = chunkUsage
+		}
+
+		chunks = append(chunks, chunk)
+	}
+
+	if len(chunks) == 0 && (chunkUsage != nil || resp.ModelVersion != "") {
+		chunks = append(chunks, sdk.StreamChunk{ID: resp.ResponseID, Model: resp.ModelVersion, Usage: chunkUsage})
+	}
+
+	return chunks
+}
+
+// safetyAttributes records the last safety ratings of every candidate and
+// the prompt feedback received in the stream.
+func (decoder *streamDecoder) safetyAttributes() []attribute.KeyValue {
+	indexes := make([]int, 0, len(decoder.safetyRatings))
+	for index := range decoder.safetyRatings {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	resp := &genai.GenerateContentResponse{PromptFeedback: decoder.promptFeedback}
+	for _, index := range indexes {
+		resp.Candidates = append(resp.Candidates, &genai.Candidate{
+			Index:         int32(index),
+			SafetyRatings: decoder.safetyRatings[index],
+		})
+	}
+
+	return safetyAttributes(resp)
+}
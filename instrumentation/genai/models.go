@@ -0,0 +1,249 @@
+// Package otelgenai instruments google.golang.org/genai, the Go SDK for
+// Gemini and Vertex AI.
+//
+// Wrap the client's Models service with NewModels and call it as before:
+// GenerateContent, GenerateContentStream, CountTokens and EmbedContent are
+// logged as LLM spans through the Traceloop SDK.
+package otelgenai
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"strings"
+
+	sdk "github.com/traceloop/go-openllmetry/traceloop-sdk"
+	"google.golang.org/genai"
+)
+
+type Models struct {
+	*genai.Models
+	traceloop     *sdk.Traceloop
+	vendor        string
+	workflowAttrs sdk.WorkflowAttributes
+}
+
+type Option func(*Models)
+
+// WithWorkflowAttributes sets the workflow name and association properties
+// recorded on every LLM span created by the wrapper.
+func WithWorkflowAttributes(attrs sdk.WorkflowAttributes) Option {
+	return func(m *Models) {
+		m.workflowAttrs = attrs
+	}
+}
+
+func NewModels(traceloop *sdk.Traceloop, client *genai.Client, opts ...Option) *Models {
+	vendor := "gemini"
+	if client.ClientConfig().Backend == genai.BackendVertexAI {
+		vendor = "vertex_ai"
+	}
+
+	m := &Models{
+		Models:    client.Models,
+		traceloop: traceloop,
+		vendor:    vendor,
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+func (m *Models) GenerateContent(ctx context.Context, model string, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	llmSpan, err := m.traceloop.LogPrompt(ctx, m.generatePrompt(model, contents, config), m.workflowAttrs)
+	if err != nil {
+		return m.Models.GenerateContent(ctx, model, contents, config)
+	}
+
+	resp, err := m.Models.GenerateContent(ctx, model, contents, config)
+	if err != nil {
+		logError(ctx, llmSpan, err)
+		return resp, err
+	}
+
+	llmSpan.SetAttributes(safetyAttributes(resp)...)
+	llmSpan.LogCompletion(ctx, completion(model, resp), usage(resp.UsageMetadata))
+	return resp, nil
+}
+
+// GenerateContentStream starts the LLM span when iteration begins and logs
+// the accumulated completion once the stream is exhausted or the caller
+// stops iterating, or the error if the stream fails.
+func (m *Models) GenerateContentStream(ctx context.Context, model string, contents []*genai.Content, config *genai.GenerateContentConfig) iter.Seq2[*genai.GenerateContentResponse, error] {
+	return func(yield func(*genai.GenerateContentResponse, error) bool) {
+		llmSpan, err := m.traceloop.LogPrompt(ctx, m.generatePrompt(model, contents, config), m.workflowAttrs)
+		if err != nil {
+			for resp, err := range m.Models.GenerateContentStream(ctx, model, contents, config) {
+				if !yield(resp, err) {
+					return
+				}
+			}
+			return
+		}
+
+		stream := llmSpan.NewStream(ctx)
+		decoder := newStreamDecoder()
+		var streamErr error
+		defer func() {
+			llmSpan.SetAttributes(decoder.safetyAttributes()...)
+			if streamErr != nil {
+				stream.CloseWithError(streamErr, errorOptions(streamErr)...)
+				return
+			}
+			stream.Close()
+		}()
+
+		for resp, err := range m.Models.GenerateContentStream(ctx, model, contents, config) {
+			if err == nil {
+				for _, chunk := range decoder.chunks(resp) {
+					stream.AddChunk(chunk)
+				}
+			} else {
+				streamErr = err
+			}
+			if !yield(resp, err) {
+				return
+			}
+		}
+	}
+}
+
+func (m *Models) CountTokens(ctx context.Context, model string, contents []*genai.Content, config *genai.CountTokensConfig) (*genai.CountTokensResponse, error) {
+	prompt := sdk.Prompt{
+		Vendor:   m.vendor,
+		Mode:     "count_tokens",
+		Model:    model,
+		Messages: messages(nil, contents),
+	}
+	if config != nil {
+		prompt.Messages = messages(config.SystemInstruction, contents)
+		prompt.Tools = tools(config.Tools)
+	}
+
+	llmSpan, err := m.traceloop.LogPrompt(ctx, prompt, m.workflowAttrs)
+	if err != nil {
+		return m.Models.CountTokens(ctx, model, contents, config)
+	}
+
+	resp, err := m.Models.CountTokens(ctx, model, contents, config)
+	if err != nil {
+		logError(ctx, llmSpan, err)
+		return resp, err
+	}
+
+	llmSpan.LogCompletion(ctx, sdk.Completion{Model: model}, sdk.Usage{
+		TotalTokens:          int(resp.TotalTokens),
+		PromptTokens:         int(resp.TotalTokens),
+		CacheReadInputTokens: int(resp.CachedContentTokenCount),
+	})
+	return resp, nil
+}
+
+func (m *Models) EmbedContent(ctx context.Context, model string, contents []*genai.Content, config *genai.EmbedContentConfig) (*genai.EmbedContentResponse, error) {
+	prompt := sdk.EmbeddingPrompt{
+		Vendor: m.vendor,
+		Model:  model,
+	}
+	for _, message := range messages(nil, contents) {
+		prompt.Inputs = append(prompt.Inputs, message.Content)
+	}
+	if config != nil && config.OutputDimensionality != nil {
+		prompt.Dimensions = int(*config.OutputDimensionality)
+	}
+
+	llmSpan, err := m.traceloop.LogEmbeddingPrompt(ctx, prompt, m.workflowAttrs)
+	if err != nil {
+		return m.Models.EmbedContent(ctx, model, contents, config)
+	}
+
+	resp, err := m.Models.EmbedContent(ctx, model, contents, config)
+	if err != nil {
+		logError(ctx, llmSpan, err)
+		return resp, err
+	}
+
+	// Token statistics are only reported by Vertex AI.
+	var promptTokens int
+	for _, embedding := range resp.Embeddings {
+		if embedding.Statistics != nil {
+			promptTokens += int(embedding.Statistics.TokenCount)
+		}
+	}
+
+	result := sdk.Embeddings{
+		Model: model,
+		Count: len(resp.Embeddings),
+	}
+	if len(resp.Embeddings) > 0 && resp.Embeddings[0] != nil {
+		result.Dimensions = len(resp.Embeddings[0].Values)
+	}
+
+	llmSpan.LogEmbeddings(ctx, result, sdk.Usage{
+		TotalTokens:  promptTokens,
+		PromptTokens: promptTokens,
+	})
+	return resp, nil
+}
+
+func (m *Models) generatePrompt(model string, contents []*genai.Content, config *genai.GenerateContentConfig) sdk.Prompt {
+	prompt := sdk.Prompt{
+		Vendor:   m.vendor,
+		Mode:     "chat",
+		Model:    model,
+		Messages: messages(nil, contents),
+	}
+
+	if config == nil {
+		return prompt
+	}
+
+	prompt.Messages = messages(config.SystemInstruction, contents)
+	prompt.Tools = tools(config.Tools)
+	prompt.Stop = config.StopSequences
+	prompt.Temperature = config.Temperature
+	prompt.TopP = config.TopP
+	prompt.FrequencyPenalty = config.FrequencyPenalty
+	prompt.PresencePenalty = config.PresencePenalty
+	prompt.MaxTokens = int(config.MaxOutputTokens)
+	if config.Seed != nil {
+		prompt.Seed = sdk.Ptr(int(*config.Seed))
+	}
+	prompt.N = int(config.CandidateCount)
+	prompt.ResponseFormat = config.ResponseMIMEType
+	prompt.ToolChoice = toolChoice(config.ToolConfig)
+
+	return prompt
+}
+
+// toolChoice returns the function calling mode, or the function the model
+// must call when the mode allows a single one.
+func toolChoice(config *genai.ToolConfig) string {
+	if config == nil || config.FunctionCallingConfig == nil {
+		return ""
+	}
+
+	functionCalling := config.FunctionCallingConfig
+	if functionCalling.Mode == genai.FunctionCallingConfigModeAny && len(functionCalling.AllowedFunctionNames) == 1 {
+		return functionCalling.AllowedFunctionNames[0]
+	}
+
+	return strings.ToLower(string(functionCalling.Mode))
+}
+
+// logError ends an LLM span whose request failed.
+func logError(ctx context.Context, llmSpan sdk.LLMSpan, err error) {
+	llmSpan.LogError(ctx, err, errorOptions(err)...)
+}
+
+// errorOptions passes on the status code of Gemini API errors.
+func errorOptions(err error) []sdk.ErrorOption {
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) {
+		return []sdk.ErrorOption{sdk.WithStatusCode(apiErr.Code)}
+	}
+
+	return nil
+}
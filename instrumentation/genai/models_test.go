@@ -0,0 +1,226 @@
+package otelgenai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/traceloop/go-openllmetry/traceloop-sdk/tracelooptest"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/genai"
+)
+
+func newTestModels(t *testing.T, handler http.HandlerFunc) (*Models, *tracetest.InMemoryExporter) {
+	t.Helper()
+
+	server, tl, exporter := tracelooptest.NewServer(t, handler)
+
+	client, err := genai.NewClient(context.Background(), &genai.ClientConfig{
+		APIKey:      "test-key",
+		Backend:     genai.BackendGeminiAPI,
+		HTTPOptions: genai.HTTPOptions{BaseURL: server.URL},
+	})
+	if err != nil {
+		t.Fatalf("genai.NewClient failed: %v", err)
+	}
+
+	return NewModels(tl, client), exporter
+}
+
+func TestGenerateContent(t *testing.T) {
+	models, exporter := newTestModels(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/models/gemini-2.5-flash:generateContent") {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{
+			"responseId": "resp-1",
+			"modelVersion": "gemini-2.5-flash-001",
+			"candidates": [{
+				"content": {"role": "model", "parts": [{"functionCall": {"name": "get_weather", "args": {"location": "Paris"}}}]},
+				"finishReason": "STOP",
+				"safetyRatings": [{"category": "HARM_CATEGORY_HARASSMENT", "probability": "NEGLIGIBLE"}]
+			}],
+			"usageMetadata": {
+				"promptTokenCount": 12,
+				"candidatesTokenCount": 4,
+				"thoughtsTokenCount": 2,
+				"totalTokenCount": 18,
+				"cachedContentTokenCount": 4,
+				"promptTokensDetails": [{"modality": "TEXT", "tokenCount": 4}, {"modality": "IMAGE", "tokenCount": 8}]
+			}
+		}`)
+	})
+
+	contents := []*genai.Content{genai.NewContentFromParts([]*genai.Part{
+		genai.NewPartFromText("Weather in Paris?"),
+		genai.NewPartFromURI("gs://weather/paris.jpg", "image/jpeg"),
+	}, genai.RoleUser)}
+	_, err := models.GenerateContent(context.Background(), "gemini-2.5-flash", contents, &genai.GenerateContentConfig{
+		SystemInstruction: genai.NewContentFromText("You are a weather bot.", genai.RoleUser),
+		Temperature:       genai.Ptr[float32](0.5),
+		Tools: []*genai.Tool{{
+			FunctionDeclarations: []*genai.FunctionDeclaration{{
+				Name:        "get_weather",
+				Description: "Get the current weather",
+			}},
+		}},
+		ToolConfig: &genai.ToolConfig{FunctionCallingConfig: &genai.FunctionCallingConfig{
+			Mode: genai.FunctionCallingConfigModeAuto,
+		}},
+		MaxOutputTokens: 512,
+		Seed:            genai.Ptr[int32](7),
+	})
+	if err != nil {
+		t.Fatalf("GenerateContent failed: %v", err)
+	}
+
+	tracelooptest.AssertAttributes(t, tracelooptest.SpanAttributes(t, exporter), map[string]interface{}{
+		"llm.vendor":                                     "gemini",
+		"llm.request.type":                               "chat",
+		"llm.request.model":                              "gemini-2.5-flash",
+		"llm.response.id":                                "resp-1",
+		"llm.response.model":                             "gemini-2.5-flash-001",
+		"llm.prompts.0.role":                             "system",
+		"llm.prompts.0.content":                          "You are a weather bot.",
+		"llm.prompts.1.role":                             "user",
+		"llm.prompts.1.content":                          "Weather in Paris?",
+		"llm.prompts.1.content_parts.1.type":             "image",
+		"llm.prompts.1.content_parts.1.url":              "gs://weather/paris.jpg",
+		"llm.request.functions.0.name":                   "get_weather",
+		"llm.request.tool_choice":                        "auto",
+		"llm.request.max_tokens":                         int64(512),
+		"llm.request.seed":                               int64(7),
+		"llm.temperature":                                0.5,
+		"llm.completions.0.role":                         "model",
+		"llm.completions.0.finish_reason":                "STOP",
+		"llm.completions.0.tool_calls.0.name":            "get_weather",
+		"llm.completions.0.tool_calls.0.arguments":       `{"location":"Paris"}`,
+		"llm.completions.0.safety_ratings.0.category":    "HARM_CATEGORY_HARASSMENT",
+		"llm.completions.0.safety_ratings.0.probability": "NEGLIGIBLE",
+		"llm.usage.prompt_tokens":                        int64(12),
+		"llm.usage.completion_tokens":                    int64(6),
+		"llm.usage.total_tokens":                         int64(18),
+		"llm.usage.cache_read_input_tokens":              int64(4),
+		"llm.usage.reasoning_tokens":                     int64(2),
+		"llm.usage.prompt_image_tokens":                  int64(8),
+	})
+}
+
+func TestNilCandidate(t *testing.T) {
+	resp := &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{
+			nil,
+			{Index: 1, Content: genai.NewContentFromText("Hello", genai.RoleModel), FinishReason: genai.FinishReasonStop},
+		},
+	}
+
+	messages := completion("gemini-2.5-flash", resp).Messages
+	if len(messages) != 1 || messages[0].Index != 1 || messages[0].Content != "Hello" {
+		t.Errorf("Expected the nil candidate to be skipped, got %+v", messages)
+	}
+	if attrs := safetyAttributes(resp); len(attrs) != 0 {
+		t.Errorf("Expected no safety attributes, got %v", attrs)
+	}
+}
+
+func TestGenerateContentStream(t *testing.T) {
+	models, exporter := newTestModels(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{
+			`{"modelVersion":"gemini-2.5-flash-001","candidates":[{"content":{"role":"model","parts":[{"text":"Hello"}]}}]}`,
+			`{"modelVersion":"gemini-2.5-flash-001","candidates":[{"content":{"role":"model","parts":[{"text":" world"}]},"finishReason":"STOP","safetyRatings":[{"category":"HARM_CATEGORY_HARASSMENT","probability":"NEGLIGIBLE"}]}],"usageMetadata":{"promptTokenCount":3,"candidatesTokenCount":2,"totalTokenCount":5}}`,
+		} {
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+	})
+
+	for _, err := range models.GenerateContentStream(context.Background(), "gemini-2.5-flash", genai.Text("Say hello"), nil) {
+		if err != nil {
+			t.Fatalf("GenerateContentStream failed: %v", err)
+		}
+	}
+
+	tracelooptest.AssertAttributes(t, tracelooptest.SpanAttributes(t, exporter), map[string]interface{}{
+		"llm.response.model":                          "gemini-2.5-flash-001",
+		"llm.completions.0.content":                   "Hello world",
+		"llm.completions.0.finish_reason":             "STOP",
+		"llm.usage.total_tokens":                      int64(5),
+		"llm.is_streaming":                            true,
+		"llm.completions.0.safety_ratings.0.category": "HARM_CATEGORY_HARASSMENT",
+	})
+}
+
+func TestCountTokens(t *testing.T) {
+	models, exporter := newTestModels(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"totalTokens": 7}`)
+	})
+
+	_, err := models.CountTokens(context.Background(), "gemini-2.5-flash", genai.Text("How many tokens is this?"), nil)
+	if err != nil {
+		t.Fatalf("CountTokens failed: %v", err)
+	}
+
+	tracelooptest.AssertAttributes(t, tracelooptest.SpanAttributes(t, exporter), map[string]interface{}{
+		"llm.request.type":        "count_tokens",
+		"llm.prompts.0.content":   "How many tokens is this?",
+		"llm.usage.prompt_tokens": int64(7),
+	})
+}
+
+func TestEmbedContent(t *testing.T) {
+	models, exporter := newTestModels(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"embeddings": [{"values": [0.1, 0.2]}, {"values": [0.3, 0.4]}]}`)
+	})
+
+	contents := []*genai.Content{
+		genai.NewContentFromText("first", genai.RoleUser),
+		genai.NewContentFromText("second", genai.RoleUser),
+	}
+	_, err := models.EmbedContent(context.Background(), "gemini-embedding-001", contents, &genai.EmbedContentConfig{
+		OutputDimensionality: genai.Ptr[int32](2),
+	})
+	if err != nil {
+		t.Fatalf("EmbedContent failed: %v", err)
+	}
+
+	tracelooptest.AssertAttributes(t, tracelooptest.SpanAttributes(t, exporter), map[string]interface{}{
+		"llm.request.type":                  "embedding",
+		"llm.request.model":                 "gemini-embedding-001",
+		"llm.prompts.0.content":             "first",
+		"llm.prompts.1.content":             "second",
+		"llm.request.embedding.dimensions":  int64(2),
+		"llm.response.embedding.count":      int64(2),
+		"llm.response.embedding.dimensions": int64(2),
+	})
+}
+
+func TestGenerateContentError(t *testing.T) {
+	models, exporter := newTestModels(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		io.WriteString(w, `{"error": {"code": 429, "message": "Resource has been exhausted", "status": "RESOURCE_EXHAUSTED"}}`)
+	})
+
+	_, err := models.GenerateContent(context.Background(), "gemini-2.5-flash", genai.Text("Hi"), nil)
+	if err == nil {
+		t.Fatal("Expected GenerateContent to fail")
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected the failed call's span to be ended, got %d spans", len(spans))
+	}
+	if spans[0].Status.Code != codes.Error {
+		t.Errorf("Expected the span status to be an error, got %v", spans[0].Status)
+	}
+	tracelooptest.AssertAttributes(t, tracelooptest.SpanAttributes(t, exporter), map[string]interface{}{
+		"llm.error.type": "rate_limit",
+	})
+}
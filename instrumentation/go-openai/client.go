@@ -0,0 +1,213 @@
+// Package otelgoopenai instruments github.com/sashabaranov/go-openai clients.
+//
+// Wrap an existing client with NewClient and keep calling it as before: chat,
+// completion, embedding, image, audio and moderation calls are logged as LLM spans
+// through the Traceloop SDK, nested under whatever span the call's context
+// carries (e.g. Workflow.Context() or Task.Context()).
+package otelgoopenai
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sashabaranov/go-openai"
+	sdk "github.com/traceloop/go-openllmetry/traceloop-sdk"
+)
+
+const vendor = "openai"
+
+type Client struct {
+	*openai.Client
+	traceloop     *sdk.Traceloop
+	workflowAttrs sdk.WorkflowAttributes
+}
+
+type Option func(*Client)
+
+// WithWorkflowAttributes sets the workflow name and association properties
+// recorded on every LLM span created by the client.
+func WithWorkflowAttributes(attrs sdk.WorkflowAttributes) Option {
+	return func(c *Client) {
+		c.workflowAttrs = attrs
+	}
+}
+
+func NewClient(traceloop *sdk.Traceloop, client *openai.Client, opts ...Option) *Client {
+	c := &Client{
+		Client:    client,
+		traceloop: traceloop,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+func (c *Client) CreateChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	llmSpan, err := c.traceloop.LogPrompt(ctx, chatPrompt(request), c.workflowAttrs)
+	if err != nil {
+		return c.Client.CreateChatCompletion(ctx, request)
+	}
+
+	resp, err := c.Client.CreateChatCompletion(ctx, request)
+	if err != nil {
+		logError(ctx, llmSpan, err)
+		return resp, err
+	}
+
+	llmSpan.LogCompletion(ctx, chatCompletion(resp), usage(resp.Usage))
+	return resp, nil
+}
+
+func (c *Client) CreateChatCompletionStream(ctx context.Context, request openai.ChatCompletionRequest) (*ChatCompletionStream, error) {
+	llmSpan, err := c.traceloop.LogPrompt(ctx, chatPrompt(request), c.workflowAttrs)
+	if err != nil {
+		stream, err := c.Client.CreateChatCompletionStream(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		return &ChatCompletionStream{ChatCompletionStream: stream}, nil
+	}
+
+	stream, err := c.Client.CreateChatCompletionStream(ctx, request)
+	if err != nil {
+		logError(ctx, llmSpan, err)
+		return nil, err
+	}
+
+	return newChatCompletionStream(stream, llmSpan.NewStream(ctx), request.Model), nil
+}
+
+func (c *Client) CreateCompletion(ctx context.Context, request openai.CompletionRequest) (openai.CompletionResponse, error) {
+	llmSpan, err := c.traceloop.LogPrompt(ctx, completionPrompt(request), c.workflowAttrs)
+	if err != nil {
+		return c.Client.CreateCompletion(ctx, request)
+	}
+
+	resp, err := c.Client.CreateCompletion(ctx, request)
+	if err != nil {
+		logError(ctx, llmSpan, err)
+		return resp, err
+	}
+
+	var respUsage sdk.Usage
+	if resp.Usage != nil {
+		respUsage = usage(*resp.Usage)
+	}
+
+	llmSpan.LogCompletion(ctx, completionCompletion(resp), respUsage)
+	return resp, nil
+}
+
+func (c *Client) CreateEmbeddings(ctx context.Context, conv openai.EmbeddingRequestConverter) (openai.EmbeddingResponse, error) {
+	request := conv.Convert()
+
+	llmSpan, err := c.traceloop.LogEmbeddingPrompt(ctx, embeddingPrompt(request), c.workflowAttrs)
+	if err != nil {
+		return c.Client.CreateEmbeddings(ctx, conv)
+	}
+
+	resp, err := c.Client.CreateEmbeddings(ctx, conv)
+	if err != nil {
+		logError(ctx, llmSpan, err)
+		return resp, err
+	}
+
+	llmSpan.LogEmbeddings(ctx, embeddings(resp), usage(resp.Usage))
+	return resp, nil
+}
+
+func (c *Client) CreateImage(ctx context.Context, request openai.ImageRequest) (openai.ImageResponse, error) {
+	llmSpan, err := c.traceloop.LogPrompt(ctx, imagePrompt(request), c.workflowAttrs)
+	if err != nil {
+		return c.Client.CreateImage(ctx, request)
+	}
+
+	resp, err := c.Client.CreateImage(ctx, request)
+	if err != nil {
+		logError(ctx, llmSpan, err)
+		return resp, err
+	}
+
+	llmSpan.LogCompletion(ctx, imageCompletion(request, resp), sdk.Usage{
+		TotalTokens:       resp.Usage.TotalTokens,
+		CompletionTokens:  resp.Usage.OutputTokens,
+		PromptTokens:      resp.Usage.InputTokens,
+		PromptImageTokens: resp.Usage.InputTokensDetails.ImageTokens,
+		ImageCount:        len(resp.Data),
+	})
+	return resp, nil
+}
+
+// CreateTranscription logs the transcription of an audio file. The duration of
+// the audio, which whisper-1 is billed by, is only returned, and so priced,
+// with the verbose_json format.
+func (c *Client) CreateTranscription(ctx context.Context, request openai.AudioRequest) (openai.AudioResponse, error) {
+	return c.createAudio(ctx, "transcription", request, c.Client.CreateTranscription)
+}
+
+// CreateTranslation logs the translation of an audio file, like
+// CreateTranscription.
+func (c *Client) CreateTranslation(ctx context.Context, request openai.AudioRequest) (openai.AudioResponse, error) {
+	return c.createAudio(ctx, "translation", request, c.Client.CreateTranslation)
+}
+
+func (c *Client) createAudio(ctx context.Context, mode string, request openai.AudioRequest, create func(context.Context, openai.AudioRequest) (openai.AudioResponse, error)) (openai.AudioResponse, error) {
+	llmSpan, err := c.traceloop.LogPrompt(ctx, audioPrompt(mode, request), c.workflowAttrs)
+	if err != nil {
+		return create(ctx, request)
+	}
+
+	resp, err := create(ctx, request)
+	if err != nil {
+		logError(ctx, llmSpan, err)
+		return resp, err
+	}
+
+	llmSpan.LogCompletion(ctx, audioCompletion(request, resp), sdk.Usage{AudioSeconds: resp.Duration})
+	return resp, nil
+}
+
+func (c *Client) Moderations(ctx context.Context, request openai.ModerationRequest) (openai.ModerationResponse, error) {
+	llmSpan, err := c.traceloop.LogPrompt(ctx, moderationPrompt(request), c.workflowAttrs)
+	if err != nil {
+		return c.Client.Moderations(ctx, request)
+	}
+
+	resp, err := c.Client.Moderations(ctx, request)
+	if err != nil {
+		logError(ctx, llmSpan, err)
+		return resp, err
+	}
+
+	llmSpan.LogCompletion(ctx, moderationCompletion(resp), sdk.Usage{})
+	return resp, nil
+}
+
+// logError ends an LLM span whose request failed.
+func logError(ctx context.Context, llmSpan sdk.LLMSpan, err error) {
+	llmSpan.LogError(ctx, err, errorOptions(err)...)
+}
+
+// errorOptions passes on the status and the error code of OpenAI API errors.
+func errorOptions(err error) []sdk.ErrorOption {
+	var opts []sdk.ErrorOption
+
+	var apiErr *openai.APIError
+	var requestErr *openai.RequestError
+	if errors.As(err, &apiErr) {
+		opts = append(opts, sdk.WithStatusCode(apiErr.HTTPStatusCode))
+		switch apiErr.Code {
+		case "context_length_exceeded":
+			opts = append(opts, sdk.WithErrorType(sdk.ErrorTypeContextLength))
+		case "content_filter":
+			opts = append(opts, sdk.WithErrorType(sdk.ErrorTypeContentFilter))
+		}
+	} else if errors.As(err, &requestErr) {
+		opts = append(opts, sdk.WithStatusCode(requestErr.HTTPStatusCode))
+	}
+
+	return opts
+}
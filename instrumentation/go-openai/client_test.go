@@ -0,0 +1,311 @@
+package otelgoopenai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	sdk "github.com/traceloop/go-openllmetry/traceloop-sdk"
+	"github.com/traceloop/go-openllmetry/traceloop-sdk/tracelooptest"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) (*Client, *sdk.Traceloop, *tracetest.InMemoryExporter) {
+	t.Helper()
+
+	server, tl, exporter := tracelooptest.NewServer(t, handler)
+
+	config := openai.DefaultConfig("test-key")
+	config.BaseURL = server.URL + "/v1"
+
+	return NewClient(tl, openai.NewClientWithConfig(config)), tl, exporter
+}
+
+func spanAttributes(t *testing.T, exporter *tracetest.InMemoryExporter, name string) map[string]interface{} {
+	t.Helper()
+
+	for _, span := range exporter.GetSpans() {
+		if span.Name != name {
+			continue
+		}
+		return tracelooptest.Attributes(span)
+	}
+
+	t.Fatalf("Span %s not found", name)
+	return nil
+}
+
+func TestCreateChatCompletion(t *testing.T) {
+	client, tl, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{
+			"id": "chatcmpl-1",
+			"model": "gpt-4o-mini-2024-07-18",
+			"choices": [{
+				"index": 0,
+				"finish_reason": "tool_calls",
+				"message": {
+					"role": "assistant",
+					"tool_calls": [{
+						"id": "call_1",
+						"type": "function",
+						"function": {"name": "get_weather", "arguments": "{\"location\":\"Paris\"}"}
+					}]
+				}
+			}],
+			"usage": {
+				"prompt_tokens": 20,
+				"completion_tokens": 5,
+				"total_tokens": 25,
+				"prompt_tokens_details": {"cached_tokens": 8},
+				"completion_tokens_details": {"reasoning_tokens": 3}
+			}
+		}`)
+	})
+
+	wf := tl.NewWorkflow(context.Background(), sdk.WorkflowAttributes{Name: "weather"})
+	_, err := client.CreateChatCompletion(wf.Context(), openai.ChatCompletionRequest{
+		Model: openai.GPT4oMini,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: "You are a weather bot."},
+			{Role: openai.ChatMessageRoleUser, Content: "Weather in Paris?"},
+		},
+		Tools: []openai.Tool{{
+			Type: openai.ToolTypeFunction,
+			Function: &openai.FunctionDefinition{
+				Name:        "get_weather",
+				Description: "Get the current weather",
+			},
+		}},
+		ToolChoice:          "required",
+		Temperature:         0.5,
+		MaxCompletionTokens: 128,
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletion failed: %v", err)
+	}
+	wf.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+	if spans[0].Parent.SpanID() != spans[1].SpanContext.SpanID() {
+		t.Errorf("Expected LLM span to be a child of the workflow span")
+	}
+
+	tracelooptest.AssertAttributes(t, spanAttributes(t, exporter, "openai.chat"), map[string]interface{}{
+		"llm.vendor":                               "openai",
+		"llm.request.type":                         "chat",
+		"llm.request.model":                        "gpt-4o-mini",
+		"llm.response.id":                          "chatcmpl-1",
+		"llm.response.model":                       "gpt-4o-mini-2024-07-18",
+		"llm.prompts.0.role":                       "system",
+		"llm.prompts.1.content":                    "Weather in Paris?",
+		"llm.request.functions.0.name":             "get_weather",
+		"llm.request.tool_choice":                  "required",
+		"llm.request.max_tokens":                   int64(128),
+		"llm.temperature":                          0.5,
+		"llm.completions.0.role":                   "assistant",
+		"llm.completions.0.finish_reason":          "tool_calls",
+		"llm.completions.0.tool_calls.0.id":        "call_1",
+		"llm.completions.0.tool_calls.0.arguments": `{"location":"Paris"}`,
+		"llm.usage.prompt_tokens":                  int64(20),
+		"llm.usage.completion_tokens":              int64(5),
+		"llm.usage.total_tokens":                   int64(25),
+		"llm.usage.cache_read_input_tokens":        int64(8),
+		"llm.usage.reasoning_tokens":               int64(3),
+	})
+}
+
+func TestCreateChatCompletionStream(t *testing.T) {
+	client, _, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{
+			`{"model":"gpt-4o-mini-2024-07-18","choices":[{"index":0,"delta":{"role":"assistant","content":"Hello"}}]}`,
+			`{"model":"gpt-4o-mini-2024-07-18","choices":[{"index":0,"delta":{"content":" world"},"finish_reason":"stop"}]}`,
+			`{"model":"gpt-4o-mini-2024-07-18","choices":[],"usage":{"prompt_tokens":3,"completion_tokens":2,"total_tokens":5}}`,
+		} {
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+		io.WriteString(w, "data: [DONE]\n\n")
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), openai.ChatCompletionRequest{
+		Model:    openai.GPT4oMini,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Say hello"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletionStream failed: %v", err)
+	}
+	defer stream.Close()
+
+	for {
+		_, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv failed: %v", err)
+		}
+	}
+
+	tracelooptest.AssertAttributes(t, spanAttributes(t, exporter, "openai.chat"), map[string]interface{}{
+		"llm.response.model":              "gpt-4o-mini-2024-07-18",
+		"llm.completions.0.role":          "assistant",
+		"llm.completions.0.content":       "Hello world",
+		"llm.usage.total_tokens":          int64(5),
+		"llm.completions.0.finish_reason": "stop",
+		"llm.is_streaming":                true,
+	})
+}
+
+func TestCreateEmbeddings(t *testing.T) {
+	client, _, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{
+			"model": "text-embedding-3-small",
+			"data": [{"index": 0, "embedding": [0.1, 0.2]}, {"index": 1, "embedding": [0.3, 0.4]}],
+			"usage": {"prompt_tokens": 4, "total_tokens": 4}
+		}`)
+	})
+
+	_, err := client.CreateEmbeddings(context.Background(), openai.EmbeddingRequestStrings{
+		Model: openai.SmallEmbedding3,
+		Input: []string{"first", "second"},
+	})
+	if err != nil {
+		t.Fatalf("CreateEmbeddings failed: %v", err)
+	}
+
+	tracelooptest.AssertAttributes(t, spanAttributes(t, exporter, "openai.embedding"), map[string]interface{}{
+		"llm.request.type":                  "embedding",
+		"llm.request.model":                 "text-embedding-3-small",
+		"llm.prompts.0.content":             "first",
+		"llm.prompts.1.content":             "second",
+		"llm.usage.prompt_tokens":           int64(4),
+		"llm.request.embedding.input_count": int64(2),
+		"llm.response.embedding.count":      int64(2),
+		"llm.response.embedding.dimensions": int64(2),
+	})
+}
+
+func TestCreateImage(t *testing.T) {
+	client, _, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{
+			"created": 1700000000,
+			"data": [{"url": "https://example.com/1.png"}, {"url": "https://example.com/2.png"}]
+		}`)
+	})
+
+	_, err := client.CreateImage(context.Background(), openai.ImageRequest{
+		Model:  openai.CreateImageModelDallE3,
+		Prompt: "A lighthouse at dusk",
+		N:      2,
+	})
+	if err != nil {
+		t.Fatalf("CreateImage failed: %v", err)
+	}
+
+	tracelooptest.AssertAttributes(t, spanAttributes(t, exporter, "openai.image"), map[string]interface{}{
+		"llm.request.type":          "image",
+		"llm.completions.1.content": "https://example.com/2.png",
+		"llm.usage.cost":            0.08,
+	})
+}
+
+func TestCreateTranscription(t *testing.T) {
+	client, _, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/audio/transcriptions" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"task": "transcribe", "language": "english", "duration": 60, "text": "Hello there."}`)
+	})
+
+	_, err := client.CreateTranscription(context.Background(), openai.AudioRequest{
+		Model:    openai.Whisper1,
+		FilePath: "greeting.mp3",
+		Reader:   strings.NewReader("audio"),
+		Format:   openai.AudioResponseFormatVerboseJSON,
+	})
+	if err != nil {
+		t.Fatalf("CreateTranscription failed: %v", err)
+	}
+
+	tracelooptest.AssertAttributes(t, spanAttributes(t, exporter, "openai.transcription"), map[string]interface{}{
+		"llm.request.type":          "transcription",
+		"llm.request.model":         "whisper-1",
+		"llm.completions.0.content": "Hello there.",
+		"llm.usage.cost":            0.006,
+	})
+}
+
+func TestCreateChatCompletionError(t *testing.T) {
+	client, _, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		io.WriteString(w, `{"error": {"message": "Rate limit reached", "type": "requests"}}`)
+	})
+
+	_, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model:    openai.GPT4oMini,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "Hi"}},
+	})
+	if err == nil {
+		t.Fatal("Expected CreateChatCompletion to fail")
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected the failed call's span to be ended, got %d spans", len(spans))
+	}
+	if spans[0].Status.Code != codes.Error {
+		t.Errorf("Expected the span status to be an error, got %v", spans[0].Status)
+	}
+	if len(spans[0].Events) != 1 || spans[0].Events[0].Name != "exception" {
+		t.Errorf("Expected an exception event, got %v", spans[0].Events)
+	}
+	tracelooptest.AssertAttributes(t, spanAttributes(t, exporter, "openai.chat"), map[string]interface{}{
+		"llm.error.type": "rate_limit",
+	})
+}
+
+func TestCreateChatCompletionImageContent(t *testing.T) {
+	client, _, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"model": "gpt-4o", "choices": [{"index": 0, "message": {"role": "assistant", "content": "A cat."}}]}`)
+	})
+
+	_, err := client.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
+		Model: openai.GPT4o,
+		Messages: []openai.ChatCompletionMessage{{
+			Role: openai.ChatMessageRoleUser,
+			MultiContent: []openai.ChatMessagePart{
+				{Type: openai.ChatMessagePartTypeText, Text: "What is this?"},
+				{Type: openai.ChatMessagePartTypeImageURL, ImageURL: &openai.ChatMessageImageURL{URL: "data:image/jpeg;base64,/9j/4AAQ"}},
+			},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletion failed: %v", err)
+	}
+
+	tracelooptest.AssertAttributes(t, spanAttributes(t, exporter, "openai.chat"), map[string]interface{}{
+		"llm.prompts.0.content":                   "What is this?",
+		"llm.prompts.0.content_parts.0.text":      "What is this?",
+		"llm.prompts.0.content_parts.1.type":      "image",
+		"llm.prompts.0.content_parts.1.mime_type": "image/jpeg",
+		"llm.prompts.0.content_parts.1.size":      int64(6),
+	})
+}
@@ -0,0 +1,365 @@
+package otelgoopenai
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/sashabaranov/go-openai"
+	sdk "github.com/traceloop/go-openllmetry/traceloop-sdk"
+)
+
+func chatPrompt(request openai.ChatCompletionRequest) sdk.Prompt {
+	var messages []sdk.Message
+	for i, message := range request.Messages {
+		messages = append(messages, chatMessage(i, message))
+	}
+
+	var tools []sdk.Tool
+	for _, tool := range request.Tools {
+		if tool.Function == nil {
+			continue
+		}
+		tools = append(tools, sdk.Tool{
+			Type:     string(tool.Type),
+			Function: toolFunction(*tool.Function),
+		})
+	}
+	for _, function := range request.Functions {
+		tools = append(tools, sdk.Tool{
+			Type:     string(openai.ToolTypeFunction),
+			Function: toolFunction(function),
+		})
+	}
+
+	maxTokens := request.MaxCompletionTokens
+	if maxTokens == 0 {
+		maxTokens = request.MaxTokens
+	}
+
+	var responseFormat string
+	if request.ResponseFormat != nil {
+		responseFormat = string(request.ResponseFormat.Type)
+	}
+
+	return sdk.Prompt{
+		Vendor:           vendor,
+		Mode:             "chat",
+		Model:            request.Model,
+		Temperature:      optional(request.Temperature),
+		TopP:             optional(request.TopP),
+		Stop:             request.Stop,
+		FrequencyPenalty: optional(request.FrequencyPenalty),
+		PresencePenalty:  optional(request.PresencePenalty),
+		MaxTokens:        maxTokens,
+		Seed:             request.Seed,
+		N:                request.N,
+		ResponseFormat:   responseFormat,
+		ToolChoice:       toolChoice(request.ToolChoice),
+		User:             request.User,
+		LogitBias:        request.LogitBias,
+		Messages:         messages,
+		Tools:            tools,
+	}
+}
+
+// toolChoice reads the tool choice of a request, which is either a mode
+// such as "auto" or a ToolChoice forcing a function.
+func toolChoice(choice any) string {
+	switch choice := choice.(type) {
+	case string:
+		return choice
+	case openai.ToolChoice:
+		return choice.Function.Name
+	case *openai.ToolChoice:
+		if choice != nil {
+			return choice.Function.Name
+		}
+	}
+
+	return ""
+}
+
+// optional returns the sampling parameter of a request, or nil when it is 0:
+// go-openai omits zero values from requests, leaving them to the API default.
+func optional(value float32) *float32 {
+	if value == 0 {
+		return nil
+	}
+
+	return &value
+}
+
+func chatCompletion(resp openai.ChatCompletionResponse) sdk.Completion {
+	var messages []sdk.Message
+	for _, choice := range resp.Choices {
+		message := chatMessage(choice.Index, choice.Message)
+		message.FinishReason = string(choice.FinishReason)
+		messages = append(messages, message)
+	}
+
+	return sdk.Completion{
+		ID:       resp.ID,
+		Model:    resp.Model,
+		Messages: messages,
+	}
+}
+
+func chatMessage(index int, message openai.ChatCompletionMessage) sdk.Message {
+	var parts []sdk.ContentPart
+	for _, part := range message.MultiContent {
+		switch part.Type {
+		case openai.ChatMessagePartTypeText:
+			parts = append(parts, sdk.ContentPart{Type: sdk.ContentPartText, Text: part.Text})
+		case openai.ChatMessagePartTypeImageURL:
+			if part.ImageURL != nil {
+				parts = append(parts, sdk.ContentPart{Type: sdk.ContentPartImage, URL: part.ImageURL.URL})
+			}
+		}
+	}
+
+	var toolCalls []sdk.ToolCall
+	for _, toolCall := range message.ToolCalls {
+		toolCalls = append(toolCalls, sdk.ToolCall{
+			ID:   toolCall.ID,
+			Type: string(toolCall.Type),
+			Function: sdk.ToolCallFunction{
+				Name:      toolCall.Function.Name,
+				Arguments: toolCall.Function.Arguments,
+			},
+		})
+	}
+	if message.FunctionCall != nil {
+		toolCalls = append(toolCalls, sdk.ToolCall{
+			Type: string(openai.ToolTypeFunction),
+			Function: sdk.ToolCallFunction{
+				Name:      message.FunctionCall.Name,
+				Arguments: message.FunctionCall.Arguments,
+			},
+		})
+	}
+
+	return sdk.Message{
+		Index:        index,
+		Role:         message.Role,
+		Content:      message.Content,
+		ContentParts: parts,
+		ToolCalls:    toolCalls,
+		ToolCallID:   message.ToolCallID,
+	}
+}
+
+func toolFunction(function openai.FunctionDefinition) sdk.ToolFunction {
+	return sdk.ToolFunction{
+		Name:        function.Name,
+		Description: function.Description,
+		Parameters:  function.Parameters,
+	}
+}
+
+func completionPrompt(request openai.CompletionRequest) sdk.Prompt {
+	return sdk.Prompt{
+		Vendor:           vendor,
+		Mode:             "completion",
+		Model:            request.Model,
+		Temperature:      optional(request.Temperature),
+		TopP:             optional(request.TopP),
+		Stop:             request.Stop,
+		FrequencyPenalty: optional(request.FrequencyPenalty),
+		PresencePenalty:  optional(request.PresencePenalty),
+		MaxTokens:        request.MaxTokens,
+		Seed:             request.Seed,
+		N:                request.N,
+		User:             request.User,
+		LogitBias:        request.LogitBias,
+		Messages:         userMessages(request.Prompt),
+	}
+}
+
+func completionCompletion(resp openai.CompletionResponse) sdk.Completion {
+	var messages []sdk.Message
+	for _, choice := range resp.Choices {
+		messages = append(messages, sdk.Message{
+			Index:        choice.Index,
+			Role:         openai.ChatMessageRoleAssistant,
+			Content:      choice.Text,
+			FinishReason: choice.FinishReason,
+		})
+	}
+
+	return sdk.Completion{
+		ID:       resp.ID,
+		Model:    resp.Model,
+		Messages: messages,
+	}
+}
+
+func embeddingPrompt(request openai.EmbeddingRequest) sdk.EmbeddingPrompt {
+	return sdk.EmbeddingPrompt{
+		Vendor:         vendor,
+		Model:          string(request.Model),
+		Inputs:         inputTexts(request.Input),
+		Dimensions:     request.Dimensions,
+		EncodingFormat: string(request.EncodingFormat),
+		User:           request.User,
+	}
+}
+
+func embeddings(resp openai.EmbeddingResponse) sdk.Embeddings {
+	result := sdk.Embeddings{
+		Model: string(resp.Model),
+		Count: len(resp.Data),
+	}
+	if len(resp.Data) > 0 {
+		result.Dimensions = len(resp.Data[0].Embedding)
+	}
+
+	return result
+}
+
+func imagePrompt(request openai.ImageRequest) sdk.Prompt {
+	return sdk.Prompt{
+		Vendor:   vendor,
+		Mode:     "image",
+		Model:    request.Model,
+		Messages: userMessages(request.Prompt),
+	}
+}
+
+func imageCompletion(request openai.ImageRequest, resp openai.ImageResponse) sdk.Completion {
+	var messages []sdk.Message
+	for i, image := range resp.Data {
+		messages = append(messages, sdk.Message{
+			Index:   i,
+			Role:    openai.ChatMessageRoleAssistant,
+			Content: image.URL,
+		})
+	}
+
+	return sdk.Completion{
+		Model:    request.Model,
+		Messages: messages,
+	}
+}
+
+// audioPrompt records the prompt guiding a transcription or a translation,
+// but not the audio itself.
+func audioPrompt(mode string, request openai.AudioRequest) sdk.Prompt {
+	prompt := sdk.Prompt{
+		Vendor: vendor,
+		Mode:   mode,
+		Model:  request.Model,
+	}
+	if request.Prompt != "" {
+		prompt.Messages = userMessages(request.Prompt)
+	}
+
+	return prompt
+}
+
+func audioCompletion(request openai.AudioRequest, resp openai.AudioResponse) sdk.Completion {
+	return sdk.Completion{
+		Model: request.Model,
+		Messages: []sdk.Message{
+			{
+				Index:   0,
+				Role:    openai.ChatMessageRoleAssistant,
+				Content: resp.Text,
+			},
+		},
+	}
+}
+
+func moderationPrompt(request openai.ModerationRequest) sdk.Prompt {
+	return sdk.Prompt{
+		Vendor:   vendor,
+		Mode:     "moderation",
+		Model:    request.Model,
+		Messages: userMessages(request.Input),
+	}
+}
+
+func moderationCompletion(resp openai.ModerationResponse) sdk.Completion {
+	var messages []sdk.Message
+	for i, result := range resp.Results {
+		content, err := json.Marshal(result)
+		if err != nil {
+			fmt.Printf("Failed to marshal moderation result: %v\n", err)
+			continue
+		}
+		messages = append(messages, sdk.Message{
+			Index:   i,
+			Role:    "moderation",
+			Content: string(content),
+		})
+	}
+
+	return sdk.Completion{
+		ID:       resp.ID,
+		Model:    resp.Model,
+		Messages: messages,
+	}
+}
+
+// userMessages turns the loosely typed prompt/input fields of the completion,
+// image and moderation requests into one user message per input.
+func userMessages(input any) []sdk.Message {
+	var messages []sdk.Message
+	for i, text := range inputTexts(input) {
+		messages = append(messages, sdk.Message{
+			Index:   i,
+			Role:    openai.ChatMessageRoleUser,
+			Content: text,
+		})
+	}
+
+	return messages
+}
+
+// inputTexts flattens a loosely typed prompt/input field into one text per
+// input. Token arrays are rendered as JSON.
+func inputTexts(input any) []string {
+	var texts []string
+	switch v := input.(type) {
+	case nil:
+	case string:
+		texts = []string{v}
+	case []string:
+		texts = v
+	case []any:
+		for _, item := range v {
+			texts = append(texts, fmt.Sprint(item))
+		}
+	case [][]int:
+		for _, tokens := range v {
+			encoded, _ := json.Marshal(tokens)
+			texts = append(texts, string(encoded))
+		}
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			fmt.Printf("Failed to marshal input: %v\n", err)
+			return nil
+		}
+		texts = []string{string(encoded)}
+	}
+
+	return texts
+}
+
+func usage(u openai.Usage) sdk.Usage {
+	result := sdk.Usage{
+		TotalTokens:      u.TotalTokens,
+		CompletionTokens: u.CompletionTokens,
+		PromptTokens:     u.PromptTokens,
+	}
+	if details := u.PromptTokensDetails; details != nil {
+		result.CacheReadInputTokens = details.CachedTokens
+		result.PromptAudioTokens = details.AudioTokens
+	}
+	if details := u.CompletionTokensDetails; details != nil {
+		result.ReasoningTokens = details.ReasoningTokens
+		result.CompletionAudioTokens = details.AudioTokens
+	}
+
+	return result
+}
@@ -0,0 +1,115 @@
+package otelgoopenai
+
+import (
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/sashabaranov/go-openai"
+	sdk "github.com/traceloop/go-openllmetry/traceloop-sdk"
+)
+
+// ChatCompletionStream wraps openai.ChatCompletionStream, passing the
+// streamed deltas to an sdk.CompletionStream that logs the assembled
+// completion once the stream ends or is closed.
+type ChatCompletionStream struct {
+	*openai.ChatCompletionStream
+	// completion is nil when the prompt could not be logged.
+	completion *sdk.CompletionStream
+	model      string
+	mutex      sync.Mutex
+	// toolCalls is the index of the last tool call of each choice, for
+	// vendors that leave the index of tool call deltas unset.
+	toolCalls map[int]int
+}
+
+func newChatCompletionStream(stream *openai.ChatCompletionStream, completion *sdk.CompletionStream, model string) *ChatCompletionStream {
+	return &ChatCompletionStream{
+		ChatCompletionStream: stream,
+		completion:           completion,
+		model:                model,
+		toolCalls:            make(map[int]int),
+	}
+}
+
+func (stream *ChatCompletionStream) Recv() (openai.ChatCompletionStreamResponse, error) {
+	resp, err := stream.ChatCompletionStream.Recv()
+	if stream.completion == nil {
+		return resp, err
+	}
+	if errors.Is(err, io.EOF) {
+		stream.completion.Close()
+		return resp, err
+	}
+	if err != nil {
+		stream.completion.CloseWithError(err, errorOptions(err)...)
+		return resp, err
+	}
+
+	stream.accumulate(resp)
+	return resp, nil
+}
+
+func (stream *ChatCompletionStream) Close() error {
+	if stream.completion != nil {
+		stream.completion.Close()
+	}
+	return stream.ChatCompletionStream.Close()
+}
+
+func (stream *ChatCompletionStream) accumulate(resp openai.ChatCompletionStreamResponse) {
+	stream.mutex.Lock()
+	defer stream.mutex.Unlock()
+
+	model := resp.Model
+	if model == "" {
+		model = stream.model
+	}
+
+	var respUsage *sdk.Usage
+	if resp.Usage != nil {
+		u := usage(*resp.Usage)
+		respUsage = &u
+	}
+
+	if len(resp.Choices) == 0 {
+		if respUsage != nil {
+			stream.completion.AddChunk(sdk.StreamChunk{ID: resp.ID, Model: model, Usage: respUsage})
+		}
+		return
+	}
+
+	for i, choice := range resp.Choices {
+		chunk := sdk.StreamChunk{
+			ID:           resp.ID,
+			Model:        model,
+			Index:        choice.Index,
+			Role:         choice.Delta.Role,
+			Content:      choice.Delta.Content,
+			FinishReason: string(choice.FinishReason),
+		}
+		if i == 0 {
+			chunk.Usage = respUsage
+		}
+
+		for _, toolCall := range choice.Delta.ToolCalls {
+			index, ok := stream.toolCalls[choice.Index]
+			if toolCall.Index != nil {
+				index = *toolCall.Index
+			} else if toolCall.ID != "" && ok {
+				index++
+			}
+			stream.toolCalls[choice.Index] = index
+
+			chunk.ToolCalls = append(chunk.ToolCalls, sdk.ToolCallDelta{
+				Index:     index,
+				ID:        toolCall.ID,
+				Type:      string(toolCall.Type),
+				Name:      toolCall.Function.Name,
+				Arguments: toolCall.Function.Arguments,
+			})
+		}
+
+		stream.completion.AddChunk(chunk)
+	}
+}
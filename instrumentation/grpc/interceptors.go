@@ -0,0 +1,110 @@
+// Package traceloopgrpc propagates Traceloop workflows across google.golang.org/grpc
+// services.
+//
+// Install the client interceptors on the connections of the calling service
+// and the server interceptors on the server of the called one: tasks, tools
+// and LLM calls of the called service are then recorded under the calling
+// span, as part of the same workflow and with the same association properties.
+//
+// The association properties may identify users: install the client
+// interceptors only on connections to services you trust.
+package traceloopgrpc
+
+import (
+	"context"
+
+	sdk "github.com/traceloop/go-openllmetry/traceloop-sdk"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UnaryClientInterceptor returns an interceptor injecting the workflow carried
+// by the context of each call into its outgoing metadata.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(injectWorkflow(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor is the streaming counterpart of
+// UnaryClientInterceptor.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(injectWorkflow(ctx), desc, cc, method, opts...)
+	}
+}
+
+// UnaryServerInterceptor returns an interceptor continuing the workflow found
+// in the incoming metadata of each call. Handlers get it with
+// sdk.WorkflowFromContext.
+func UnaryServerInterceptor(traceloop *sdk.Traceloop) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(extractWorkflow(traceloop, ctx), req)
+	}
+}
+
+// StreamServerInterceptor is the streaming counterpart of
+// UnaryServerInterceptor.
+func StreamServerInterceptor(traceloop *sdk.Traceloop) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &serverStream{
+			ServerStream: ss,
+			ctx:          extractWorkflow(traceloop, ss.Context()),
+		})
+	}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func injectWorkflow(ctx context.Context) context.Context {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+
+	sdk.InjectWorkflow(ctx, metadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+func extractWorkflow(traceloop *sdk.Traceloop, ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+
+	return traceloop.ExtractWorkflow(ctx, metadataCarrier(md))
+}
+
+// metadataCarrier adapts gRPC metadata to a propagation.TextMapCarrier.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
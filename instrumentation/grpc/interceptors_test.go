@@ -0,0 +1,151 @@
+package traceloopgrpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	sdk "github.com/traceloop/go-openllmetry/traceloop-sdk"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// healthServer checks that its handlers are called with the context of the
+// "handle" task started by the test interceptors.
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+	t *testing.T
+}
+
+func (s *healthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	s.assertTask(ctx)
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func (s *healthServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	s.assertTask(stream.Context())
+	return stream.Send(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING})
+}
+
+func (s *healthServer) assertTask(ctx context.Context) {
+	if task := sdk.TaskFromContext(ctx); task == nil || task.Name != "handle" {
+		s.t.Errorf("Expected the handler to be called with the handle task, got %v", task)
+	}
+	if workflow := sdk.WorkflowFromContext(ctx); workflow == nil || workflow.Attributes.Name != "orchestration" {
+		s.t.Errorf("Expected the handler to be called with the workflow, got %v", workflow)
+	}
+}
+
+// newTestConn serves the health service with the server interceptors, and
+// starts a "handle" task in the handler context of every call.
+func newTestConn(t *testing.T, tl *sdk.Traceloop) *grpc.ClientConn {
+	t.Helper()
+
+	handleUnary := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		task := sdk.WorkflowFromContext(ctx).NewTask("handle")
+		defer task.End()
+		return handler(task.Context(), req)
+	}
+	handleStream := func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		task := sdk.WorkflowFromContext(ss.Context()).NewTask("handle")
+		defer task.End()
+		return handler(srv, &serverStream{ServerStream: ss, ctx: task.Context()})
+	}
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(UnaryServerInterceptor(tl), handleUnary),
+		grpc.ChainStreamInterceptor(StreamServerInterceptor(tl), handleStream),
+	)
+	healthpb.RegisterHealthServer(server, &healthServer{t: t})
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(StreamClientInterceptor()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create the client: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func TestInterceptors(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tl, err := sdk.NewClient(context.Background(), sdk.Config{
+		BaseURL:      "http://localhost",
+		Exporter:     exporter,
+		DisableBatch: true,
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	client := healthpb.NewHealthClient(newTestConn(t, tl))
+
+	ctx := sdk.WithAssociationProperties(context.Background(), map[string]string{"user_id": "user-1"})
+	wf := tl.NewWorkflow(ctx, sdk.WorkflowAttributes{Name: "orchestration"})
+	task := wf.NewTask("dispatch")
+
+	if _, err := client.Check(task.Context(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	stream, err := client.Watch(task.Context(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("Recv failed: %v", err)
+	}
+	// The stream ends once the handler returns, after its task is ended.
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Fatalf("Expected the stream to end, got %v", err)
+	}
+	task.End()
+	wf.End()
+
+	var handled []tracetest.SpanStub
+	var dispatch tracetest.SpanStub
+	for _, span := range exporter.GetSpans() {
+		switch span.Name {
+		case "handle.task":
+			handled = append(handled, span)
+		case "dispatch.task":
+			dispatch = span
+		}
+	}
+	if len(handled) != 2 {
+		t.Fatalf("Expected a remote task per call, got %d", len(handled))
+	}
+
+	for _, span := range handled {
+		if span.Parent.SpanID() != dispatch.SpanContext.SpanID() {
+			t.Error("Expected the remote task to be a child of the calling task")
+		}
+
+		attrs := make(map[string]string)
+		for _, attr := range span.Attributes {
+			attrs[string(attr.Key)] = attr.Value.Emit()
+		}
+		expected := map[string]string{
+			"traceloop.workflow.name":                  "orchestration",
+			"traceloop.entity.path":                    "dispatch.handle",
+			"traceloop.association.properties.user_id": "user-1",
+		}
+		for key, value := range expected {
+			if attrs[key] != value {
+				t.Errorf("Attribute %s: expected %v, got %v", key, value, attrs[key])
+			}
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package otellangchaingo
+
+import (
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+	sdk "github.com/traceloop/go-openllmetry/traceloop-sdk"
+)
+
+func messages(ms []llms.MessageContent) []sdk.Message {
+	var result []sdk.Message
+	for i, m := range ms {
+		var texts []string
+		var parts []sdk.ContentPart
+		var toolCalls []sdk.ToolCall
+		var toolCallID string
+		hasBinary := false
+		for _, part := range m.Parts {
+			switch part := part.(type) {
+			case llms.TextContent:
+				texts = append(texts, part.Text)
+				parts = append(parts, sdk.ContentPart{Type: sdk.ContentPartText, Text: part.Text})
+			case llms.ImageURLContent:
+				hasBinary = true
+				parts = append(parts, sdk.ContentPart{Type: sdk.ContentPartImage, URL: part.URL})
+			case llms.BinaryContent:
+				hasBinary = true
+				parts = append(parts, sdk.ContentPart{Type: contentPartType(part.MIMEType), Data: part.Data, MimeType: part.MIMEType})
+			case llms.ToolCall:
+				toolCalls = append(toolCalls, toolCall(part.ID, part.Type, part.FunctionCall))
+			case llms.ToolCallResponse:
+				texts = append(texts, part.Content)
+				if toolCallID == "" {
+					toolCallID = part.ToolCallID
+				}
+			}
+		}
+
+		// Text-only content is already recorded as the message's content.
+		if !hasBinary {
+			parts = nil
+		}
+
+		result = append(result, sdk.Message{
+			Index:        i,
+			Role:         role(m.Role),
+			Content:      strings.Join(texts, "\n"),
+			ContentParts: parts,
+			ToolCalls:    toolCalls,
+			ToolCallID:   toolCallID,
+		})
+	}
+
+	return result
+}
+
+func contentPartType(mimeType string) string {
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return sdk.ContentPartImage
+	case strings.HasPrefix(mimeType, "audio/"):
+		return sdk.ContentPartAudio
+	default:
+		return sdk.ContentPartFile
+	}
+}
+
+// role maps langchaingo's message types to the roles used by the vendors.
+func role(messageType llms.ChatMessageType) string {
+	switch messageType {
+	case llms.ChatMessageTypeAI:
+		return "assistant"
+	case llms.ChatMessageTypeHuman, llms.ChatMessageTypeGeneric:
+		return "user"
+	case llms.ChatMessageTypeFunction:
+		return "tool"
+	default:
+		return string(messageType)
+	}
+}
+
+func toolCall(id, toolType string, functionCall *llms.FunctionCall) sdk.ToolCall {
+	if toolType == "" {
+		toolType = "function"
+	}
+
+	result := sdk.ToolCall{ID: id, Type: toolType}
+	if functionCall != nil {
+		result.Function = sdk.ToolCallFunction{
+			Name:      functionCall.Name,
+			Arguments: functionCall.Arguments,
+		}
+	}
+
+	return result
+}
+
+func contentResponse(res *llms.ContentResponse) (sdk.Completion, sdk.Usage) {
+	if res == nil {
+		return sdk.Completion{}, sdk.Usage{}
+	}
+
+	var completion sdk.Completion
+	for i, choice := range res.Choices {
+		message := sdk.Message{
+			Index:        i,
+			Role:         "assistant",
+			Content:      choice.Content,
+			FinishReason: choice.StopReason,
+		}
+		for _, tc := range choice.ToolCalls {
+			message.ToolCalls = append(message.ToolCalls, toolCall(tc.ID, tc.Type, tc.FunctionCall))
+		}
+		if len(message.ToolCalls) == 0 && choice.FuncCall != nil {
+			message.ToolCalls = append(message.ToolCalls, toolCall("", "", choice.FuncCall))
+		}
+		completion.Messages = append(completion.Messages, message)
+
+		if model, ok := choice.GenerationInfo["model"].(string); ok && completion.Model == "" {
+			completion.Model = model
+		}
+	}
+
+	if len(res.Choices) == 0 {
+		return completion, sdk.Usage{}
+	}
+
+	return completion, usage(res.Choices[0].GenerationInfo)
+}
+
+// usage reads the token counts from a choice's generation info, whose keys
+// depend on the provider: OpenAI, Google AI and Ollama report prompt and
+// completion tokens, while Anthropic reports input and output tokens that
+// exclude the prompt cache.
+func usage(info map[string]any) sdk.Usage {
+	if inputTokens, ok := intInfo(info, "InputTokens", "input_tokens"); ok {
+		outputTokens, _ := intInfo(info, "OutputTokens", "output_tokens")
+		cacheCreationTokens, _ := intInfo(info, "CacheCreationInputTokens")
+		cacheReadTokens, _ := intInfo(info, "CacheReadInputTokens")
+		promptTokens := inputTokens + cacheCreationTokens + cacheReadTokens
+
+		return sdk.Usage{
+			TotalTokens:              promptTokens + outputTokens,
+			CompletionTokens:         outputTokens,
+			PromptTokens:             promptTokens,
+			CacheCreationInputTokens: cacheCreationTokens,
+			CacheReadInputTokens:     cacheReadTokens,
+		}
+	}
+
+	promptTokens, _ := intInfo(info, "PromptTokens")
+	completionTokens, _ := intInfo(info, "CompletionTokens")
+	totalTokens, ok := intInfo(info, "TotalTokens")
+	if !ok {
+		totalTokens = promptTokens + completionTokens
+	}
+	cachedTokens, _ := intInfo(info, "PromptCachedTokens", "CachedTokens")
+	reasoningTokens, _ := intInfo(info, "CompletionReasoningTokens", "ReasoningTokens", "ThinkingTokens")
+	promptAudioTokens, _ := intInfo(info, "PromptAudioTokens")
+	completionAudioTokens, _ := intInfo(info, "CompletionAudioTokens")
+
+	return sdk.Usage{
+		TotalTokens:           totalTokens,
+		CompletionTokens:      completionTokens,
+		PromptTokens:          promptTokens,
+		CacheReadInputTokens:  cachedTokens,
+		ReasoningTokens:       reasoningTokens,
+		PromptAudioTokens:     promptAudioTokens,
+		CompletionAudioTokens: completionAudioTokens,
+	}
+}
+
+// intInfo returns the first of the keys present in the generation info.
+// Providers store counts as int, int32 or int64.
+func intInfo(info map[string]any, keys ...string) (int, bool) {
+	for _, key := range keys {
+		switch value := info[key].(type) {
+		case int:
+			return value, true
+		case int32:
+			return int(value), true
+		case int64:
+			return int(value), true
+		case float64:
+			return int(value), true
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,442 @@
+// Package otellangchaingo traces github.com/tmc/langchaingo applications
+// through a callbacks.Handler.
+//
+// Register the handler on the chains, agents, LLMs, tools and retrievers of
+// a pipeline: the outermost chain becomes a workflow span, nested chains,
+// tools and retrievers become its tasks, and LLM calls are logged as LLM
+// spans under the run that made them.
+//
+//	handler := otellangchaingo.NewHandler(traceloop,
+//		otellangchaingo.WithWorkflowAttributes(sdk.WorkflowAttributes{Name: "qa"}),
+//	)
+//	llm, _ := openai.New(openai.WithCallback(handler))
+//	chain := chains.NewLLMChain(llm, prompt)
+//	chain.CallbacksHandler = handler
+//
+// When the context passed to the pipeline carries a workflow, e.g. from
+// Workflow.Context, the outermost chain becomes a task of that workflow
+// instead.
+//
+// langchaingo does not pass run identifiers to its callbacks, so a Handler
+// tracks the runs it has started in order, separately for every span the
+// pipelines are called under. Pipelines executed concurrently under the same
+// span, or under no span, need a Handler each.
+package otellangchaingo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/tmc/langchaingo/callbacks"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/schema"
+	semconvai "github.com/traceloop/go-openllmetry/semconv-ai"
+	sdk "github.com/traceloop/go-openllmetry/traceloop-sdk"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	defaultVendor       = "langchaingo"
+	defaultWorkflowName = "langchaingo"
+)
+
+var _ callbacks.Handler = (*Handler)(nil)
+
+type Handler struct {
+	traceloop     *sdk.Traceloop
+	workflowAttrs sdk.WorkflowAttributes
+	vendor        string
+	model         string
+
+	mu        sync.Mutex
+	pipelines map[pipelineKey]*pipeline
+}
+
+// pipelineKey identifies the span whose context the callbacks of a pipeline
+// are called with.
+type pipelineKey struct {
+	traceID trace.TraceID
+	spanID  trace.SpanID
+}
+
+// pipeline holds the runs of the pipelines called under one span.
+type pipeline struct {
+	runs []*run
+	// toolName is the tool picked by the last agent action, which names
+	// the tool run that follows it.
+	toolName string
+}
+
+// run is a chain, tool, retriever or LLM call that has started and not yet
+// ended.
+type run struct {
+	kind string
+	ctx  context.Context
+	// workflow is set on the run that started the workflow of the pipeline.
+	workflow *sdk.Workflow
+	task     *sdk.Task
+	tool     *sdk.ToolSpan
+	llmSpan  sdk.LLMSpan
+	model    string
+}
+
+type Option func(*Handler)
+
+// WithWorkflowAttributes sets the name of the workflow span started for the
+// outermost chain and the association properties recorded on LLM spans.
+func WithWorkflowAttributes(attrs sdk.WorkflowAttributes) Option {
+	return func(h *Handler) {
+		h.workflowAttrs = attrs
+	}
+}
+
+// WithVendor sets the vendor recorded on LLM spans, since langchaingo does
+// not report which provider served a call.
+func WithVendor(vendor string) Option {
+	return func(h *Handler) {
+		h.vendor = vendor
+	}
+}
+
+// WithModel sets the model recorded on LLM spans for providers that do not
+// report it in the response.
+func WithModel(model string) Option {
+	return func(h *Handler) {
+		h.model = model
+	}
+}
+
+func NewHandler(traceloop *sdk.Traceloop, opts ...Option) *Handler {
+	h := &Handler{
+		traceloop: traceloop,
+		vendor:    defaultVendor,
+		pipelines: make(map[pipelineKey]*pipeline),
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	if h.workflowAttrs.Name == "" {
+		h.workflowAttrs.Name = defaultWorkflowName
+	}
+
+	return h
+}
+
+func (h *Handler) HandleChainStart(ctx context.Context, inputs map[string]any) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	r := h.startRun(ctx, "chain", "chain")
+	sdk.SetEntityAttribute(trace.SpanFromContext(r.ctx), semconvai.TraceloopEntityInput, inputs)
+}
+
+func (h *Handler) HandleChainEnd(ctx context.Context, outputs map[string]any) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if r := h.popRun(ctx, "chain"); r != nil {
+		sdk.SetEntityAttribute(trace.SpanFromContext(r.ctx), semconvai.TraceloopEntityOutput, outputs)
+		endRun(r)
+	}
+}
+
+func (h *Handler) HandleChainError(ctx context.Context, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if r := h.popRun(ctx, "chain"); r != nil {
+		recordError(r.ctx, err)
+		endRun(r)
+	}
+}
+
+func (h *Handler) HandleToolStart(ctx context.Context, input string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	p := h.pipeline(ctx)
+	name := p.toolName
+	if name == "" {
+		name = "tool"
+	}
+	p.toolName = ""
+
+	r := &run{kind: "tool"}
+	workflow, task := h.parent(ctx, r)
+	toolCall := sdk.ToolCall{
+		Type:     "function",
+		Function: sdk.ToolCallFunction{Name: name, Arguments: input},
+	}
+	if task != nil {
+		r.tool = task.NewTool(toolCall)
+	} else {
+		r.tool = workflow.NewTool(toolCall)
+	}
+	r.ctx = r.tool.Context()
+
+	p.runs = append(p.runs, r)
+}
+
+func (h *Handler) HandleToolEnd(ctx context.Context, output string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if r := h.popRun(ctx, "tool"); r != nil {
+		r.tool.LogResult(r.ctx, output)
+		endRun(r)
+	}
+}
+
+func (h *Handler) HandleToolError(ctx context.Context, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if r := h.popRun(ctx, "tool"); r != nil {
+		r.tool.LogError(r.ctx, err)
+		endRun(r)
+	}
+}
+
+func (h *Handler) HandleRetrieverStart(ctx context.Context, query string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	r := h.startRun(ctx, "retriever", "retriever")
+	sdk.SetEntityAttribute(trace.SpanFromContext(r.ctx), semconvai.TraceloopEntityInput, query)
+}
+
+func (h *Handler) HandleRetrieverEnd(ctx context.Context, query string, documents []schema.Document) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if r := h.popRun(ctx, "retriever"); r != nil {
+		sdk.SetEntityAttribute(trace.SpanFromContext(r.ctx), semconvai.TraceloopEntityOutput, documents)
+		endRun(r)
+	}
+}
+
+// HandleAgentAction records the action on the agent's chain span. The tool
+// it names is used as the entity name of the tool run that follows.
+func (h *Handler) HandleAgentAction(ctx context.Context, action schema.AgentAction) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.pipeline(ctx).toolName = action.Tool
+	h.currentSpan(ctx).AddEvent("agent.action", trace.WithAttributes(
+		attribute.String("agent.action.tool", action.Tool),
+		attribute.String("agent.action.tool_input", action.ToolInput),
+		attribute.String("agent.action.log", action.Log),
+	))
+}
+
+func (h *Handler) HandleAgentFinish(ctx context.Context, finish schema.AgentFinish) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.currentSpan(ctx).AddEvent("agent.finish", trace.WithAttributes(
+		attribute.String("agent.finish.log", finish.Log),
+	))
+}
+
+// HandleLLMStart logs the prompts of the legacy, text-only LLM interface.
+func (h *Handler) HandleLLMStart(ctx context.Context, prompts []string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var messages []sdk.Message
+	for i, prompt := range prompts {
+		messages = append(messages, sdk.Message{Index: i, Role: "user", Content: prompt})
+	}
+
+	h.startLLM(ctx, "completion", messages)
+}
+
+func (h *Handler) HandleLLMGenerateContentStart(ctx context.Context, ms []llms.MessageContent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.startLLM(ctx, "chat", messages(ms))
+}
+
+func (h *Handler) HandleLLMGenerateContentEnd(ctx context.Context, res *llms.ContentResponse) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	r := h.popRun(ctx, "llm")
+	if r == nil {
+		return
+	}
+
+	completion, usage := contentResponse(res)
+	if completion.Model == "" {
+		completion.Model = r.model
+	}
+
+	r.llmSpan.LogCompletion(r.ctx, completion, usage)
+}
+
+func (h *Handler) HandleLLMError(ctx context.Context, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if r := h.popRun(ctx, "llm"); r != nil {
+		r.llmSpan.LogError(r.ctx, err)
+	}
+}
+
+func (h *Handler) HandleText(ctx context.Context, text string) {}
+
+func (h *Handler) HandleStreamingFunc(ctx context.Context, chunk []byte) {}
+
+// pipeline returns the runs of the pipeline called with ctx.
+func (h *Handler) pipeline(ctx context.Context) *pipeline {
+	key := pipelineKeyOf(ctx)
+	p, ok := h.pipelines[key]
+	if !ok {
+		p = &pipeline{}
+		h.pipelines[key] = p
+	}
+
+	return p
+}
+
+func pipelineKeyOf(ctx context.Context) pipelineKey {
+	spanContext := trace.SpanContextFromContext(ctx)
+	return pipelineKey{traceID: spanContext.TraceID(), spanID: spanContext.SpanID()}
+}
+
+// startRun starts a task nested under the innermost chain, tool or retriever
+// run, or under the workflow or task carried by ctx. The outermost run of a
+// pipeline called outside of any workflow starts the workflow instead.
+func (h *Handler) startRun(ctx context.Context, kind, name string) *run {
+	r := &run{kind: kind}
+	if h.parentRun(ctx) == nil && sdk.WorkflowFromContext(ctx) == nil {
+		r.workflow = h.traceloop.NewWorkflow(ctx, h.workflowAttrs)
+		r.ctx = r.workflow.Context()
+	} else {
+		workflow, task := h.parent(ctx, r)
+		if task != nil {
+			r.task = task.NewTask(name)
+		} else {
+			r.task = workflow.NewTask(name)
+		}
+		r.ctx = r.task.Context()
+	}
+
+	p := h.pipeline(ctx)
+	p.runs = append(p.runs, r)
+	return r
+}
+
+// parent returns the workflow, and the task if any, that a run started with
+// ctx is nested under. A tool run called outside of any workflow starts the
+// workflow, which it records in r.
+func (h *Handler) parent(ctx context.Context, r *run) (*sdk.Workflow, *sdk.Task) {
+	parentCtx := h.parentContext(ctx)
+	if workflow := sdk.WorkflowFromContext(parentCtx); workflow != nil {
+		return workflow, sdk.TaskFromContext(parentCtx)
+	}
+
+	r.workflow = h.traceloop.NewWorkflow(ctx, h.workflowAttrs)
+	return r.workflow, nil
+}
+
+// endRun ends the run, and the workflow when the run started it.
+func endRun(r *run) {
+	if r.task != nil {
+		r.task.End()
+	}
+	if r.workflow != nil {
+		r.workflow.End()
+	}
+}
+
+func (h *Handler) startLLM(ctx context.Context, mode string, messages []sdk.Message) {
+	parentCtx := h.parentContext(ctx)
+
+	// The LLM span takes the name of the workflow carried by the parent
+	// context. A bare LLM call is not part of any workflow.
+	var workflowAttrs sdk.WorkflowAttributes
+	if sdk.WorkflowFromContext(parentCtx) == nil {
+		workflowAttrs.AssociationProperties = h.workflowAttrs.AssociationProperties
+	}
+
+	llmSpan, err := h.traceloop.LogPrompt(parentCtx, sdk.Prompt{
+		Vendor:   h.vendor,
+		Mode:     mode,
+		Model:    h.model,
+		Messages: messages,
+	}, workflowAttrs)
+	if err != nil {
+		fmt.Printf("Failed to log prompt: %v\n", err)
+		return
+	}
+
+	p := h.pipeline(ctx)
+	p.runs = append(p.runs, &run{kind: "llm", ctx: parentCtx, llmSpan: llmSpan, model: h.model})
+}
+
+// popRun removes the innermost run of the given kind from the pipeline called
+// with ctx, and forgets the pipeline once it has no run left.
+func (h *Handler) popRun(ctx context.Context, kind string) *run {
+	key := pipelineKeyOf(ctx)
+	p, ok := h.pipelines[key]
+	if !ok {
+		return nil
+	}
+
+	for i := len(p.runs) - 1; i >= 0; i-- {
+		if r := p.runs[i]; r.kind == kind {
+			p.runs = append(p.runs[:i], p.runs[i+1:]...)
+			if len(p.runs) == 0 {
+				delete(h.pipelines, key)
+			}
+			return r
+		}
+	}
+
+	return nil
+}
+
+// parentRun returns the innermost chain, tool or retriever run of the
+// pipeline called with ctx, or nil.
+func (h *Handler) parentRun(ctx context.Context) *run {
+	p, ok := h.pipelines[pipelineKeyOf(ctx)]
+	if !ok {
+		return nil
+	}
+
+	for i := len(p.runs) - 1; i >= 0; i-- {
+		if r := p.runs[i]; r.kind != "llm" {
+			return r
+		}
+	}
+
+	return nil
+}
+
+// parentContext returns the context of the innermost chain, tool or
+// retriever run, so spans started from it are nested under that run, or ctx
+// when no such run is in progress.
+func (h *Handler) parentContext(ctx context.Context) context.Context {
+	if r := h.parentRun(ctx); r != nil {
+		return r.ctx
+	}
+
+	return ctx
+}
+
+func (h *Handler) currentSpan(ctx context.Context) trace.Span {
+	return trace.SpanFromContext(h.parentContext(ctx))
+}
+
+func recordError(ctx context.Context, err error) {
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,275 @@
+package otellangchaingo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tmc/langchaingo/chains"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/llms/openai"
+	"github.com/tmc/langchaingo/prompts"
+	"github.com/tmc/langchaingo/schema"
+	sdk "github.com/traceloop/go-openllmetry/traceloop-sdk"
+	"github.com/traceloop/go-openllmetry/traceloop-sdk/tracelooptest"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTestHandler(t *testing.T, handler http.HandlerFunc, opts ...Option) (*Handler, *httptest.Server, *tracetest.InMemoryExporter) {
+	t.Helper()
+
+	server, tl, exporter := tracelooptest.NewServer(t, handler)
+
+	return NewHandler(tl, opts...), server, exporter
+}
+
+func spansByName(exporter *tracetest.InMemoryExporter) map[string]tracetest.SpanStub {
+	spans := make(map[string]tracetest.SpanStub)
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+	return spans
+}
+
+func TestLLMChain(t *testing.T) {
+	handler, server, exporter := newTestHandler(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{
+			"id": "chatcmpl-1",
+			"object": "chat.completion",
+			"model": "gpt-4o-mini-2024-07-18",
+			"choices": [{"index": 0, "message": {"role": "assistant", "content": "Otters hold hands."}, "finish_reason": "stop"}],
+			"usage": {
+				"prompt_tokens": 12,
+				"completion_tokens": 5,
+				"total_tokens": 17,
+				"completion_tokens_details": {"reasoning_tokens": 2}
+			}
+		}`)
+	}, WithWorkflowAttributes(sdk.WorkflowAttributes{Name: "facts"}), WithVendor("openai"), WithModel("gpt-4o-mini"))
+
+	llm, err := openai.New(
+		openai.WithToken("test-key"),
+		openai.WithBaseURL(server.URL),
+		openai.WithModel("gpt-4o-mini"),
+		openai.WithCallback(handler),
+	)
+	if err != nil {
+		t.Fatalf("openai.New failed: %v", err)
+	}
+
+	chain := chains.NewLLMChain(llm, prompts.NewPromptTemplate("Tell me a fact about {{.topic}}", []string{"topic"}))
+	chain.CallbacksHandler = handler
+
+	if _, err := chains.Call(context.Background(), chain, map[string]any{"topic": "otters"}); err != nil {
+		t.Fatalf("chains.Call failed: %v", err)
+	}
+
+	spans := spansByName(exporter)
+	workflow, ok := spans["facts.workflow"]
+	if !ok {
+		t.Fatalf("Expected a workflow span, got %v", spans)
+	}
+	llmSpan, ok := spans["openai.chat"]
+	if !ok {
+		t.Fatalf("Expected an LLM span, got %v", spans)
+	}
+
+	if llmSpan.Parent.SpanID() != workflow.SpanContext.SpanID() {
+		t.Errorf("Expected the LLM span to be a child of the workflow span")
+	}
+
+	tracelooptest.AssertAttributes(t, tracelooptest.Attributes(workflow), map[string]interface{}{
+		"traceloop.span.kind":     "workflow",
+		"traceloop.entity.name":   "facts",
+		"traceloop.entity.input":  `{"topic":"otters"}`,
+		"traceloop.entity.output": `{"text":"Otters hold hands."}`,
+	})
+	tracelooptest.AssertAttributes(t, tracelooptest.Attributes(llmSpan), map[string]interface{}{
+		"traceloop.workflow.name":         "facts",
+		"llm.request.model":               "gpt-4o-mini",
+		"llm.prompts.0.role":              "user",
+		"llm.prompts.0.content":           "Tell me a fact about otters",
+		"llm.completions.0.content":       "Otters hold hands.",
+		"llm.completions.0.finish_reason": "stop",
+		"llm.usage.prompt_tokens":         int64(12),
+		"llm.usage.completion_tokens":     int64(5),
+		"llm.usage.total_tokens":          int64(17),
+		"llm.usage.reasoning_tokens":      int64(2),
+	})
+}
+
+func TestAgentWithTool(t *testing.T) {
+	handler, _, exporter := newTestHandler(t, nil)
+	ctx := context.Background()
+
+	handler.HandleChainStart(ctx, map[string]any{"input": "What is 2+2?"})
+	handler.HandleLLMGenerateContentStart(ctx, []llms.MessageContent{
+		llms.TextParts(llms.ChatMessageTypeSystem, "Use the calculator."),
+		{Role: llms.ChatMessageTypeHuman, Parts: []llms.ContentPart{
+			llms.TextContent{Text: "What is 2+2?"},
+			llms.ImageURLContent{URL: "https://example.com/sum.png"},
+		}},
+	})
+	handler.HandleLLMGenerateContentEnd(ctx, &llms.ContentResponse{Choices: []*llms.ContentChoice{{
+		StopReason: "tool_calls",
+		ToolCalls: []llms.ToolCall{{
+			ID:           "call_1",
+			Type:         "function",
+			FunctionCall: &llms.FunctionCall{Name: "calculator", Arguments: `{"expression":"2+2"}`},
+		}},
+		GenerationInfo: map[string]any{"InputTokens": 20, "OutputTokens": 6, "CacheReadInputTokens": 100},
+	}}})
+	handler.HandleAgentAction(ctx, schema.AgentAction{Tool: "calculator", ToolInput: "2+2"})
+	handler.HandleToolStart(ctx, "2+2")
+	handler.HandleToolEnd(ctx, "4")
+	handler.HandleAgentFinish(ctx, schema.AgentFinish{ReturnValues: map[string]any{"output": "4"}})
+	handler.HandleChainEnd(ctx, map[string]any{"output": "4"})
+
+	spans := spansByName(exporter)
+	if len(spans) != 3 {
+		t.Fatalf("Expected 3 spans, got %d", len(spans))
+	}
+
+	workflow := spans["langchaingo.workflow"]
+	tool, ok := spans["calculator.tool"]
+	if !ok {
+		t.Fatalf("Expected a tool span named after the tool, got %v", spans)
+	}
+	if tool.Parent.SpanID() != workflow.SpanContext.SpanID() {
+		t.Errorf("Expected the tool span to be a child of the workflow span")
+	}
+	if len(workflow.Events) != 2 || workflow.Events[0].Name != "agent.action" {
+		t.Errorf("Expected the agent events on the workflow span, got %v", workflow.Events)
+	}
+
+	tracelooptest.AssertAttributes(t, tracelooptest.Attributes(tool), map[string]interface{}{
+		"traceloop.span.kind":     "tool",
+		"traceloop.entity.name":   "calculator",
+		"traceloop.entity.path":   "calculator",
+		"traceloop.entity.input":  "2+2",
+		"traceloop.entity.output": "4",
+	})
+	tracelooptest.AssertAttributes(t, tracelooptest.Attributes(spans["langchaingo.chat"]), map[string]interface{}{
+		"llm.prompts.0.role":                       "system",
+		"llm.prompts.1.role":                       "user",
+		"llm.prompts.1.content":                    "What is 2+2?",
+		"llm.prompts.1.content_parts.1.url":        "https://example.com/sum.png",
+		"llm.completions.0.tool_calls.0.name":      "calculator",
+		"llm.completions.0.tool_calls.0.arguments": `{"expression":"2+2"}`,
+		"llm.usage.prompt_tokens":                  int64(120),
+		"llm.usage.cache_read_input_tokens":        int64(100),
+	})
+}
+
+func TestChainError(t *testing.T) {
+	handler, _, exporter := newTestHandler(t, nil)
+	ctx := context.Background()
+
+	handler.HandleChainStart(ctx, map[string]any{"input": "Hi"})
+	handler.HandleLLMGenerateContentStart(ctx, []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, "Hi")})
+	handler.HandleLLMError(ctx, errors.New("rate limited"))
+	handler.HandleChainError(ctx, errors.New("rate limited"))
+
+	spans := spansByName(exporter)
+	if len(spans) != 2 {
+		t.Fatalf("Expected the workflow and LLM spans to be ended, got %d spans", len(spans))
+	}
+	if status := spans["langchaingo.workflow"].Status; status.Code != codes.Error || status.Description != "rate limited" {
+		t.Errorf("Expected the workflow span to record the error, got %v", status)
+	}
+	if status := spans["langchaingo.chat"].Status; status.Code != codes.Error {
+		t.Errorf("Expected the LLM span to record the error, got %v", status)
+	}
+	tracelooptest.AssertAttributes(t, tracelooptest.Attributes(spans["langchaingo.chat"]), map[string]interface{}{
+		"llm.error.type": "rate_limit",
+	})
+}
+
+func TestChainInWorkflow(t *testing.T) {
+	_, tl, exporter := tracelooptest.NewServer(t, nil)
+	handler := NewHandler(tl)
+
+	workflow := tl.NewWorkflow(context.Background(), sdk.WorkflowAttributes{Name: "support"})
+	ctx := workflow.Context()
+
+	handler.HandleChainStart(ctx, map[string]any{"input": "Hi"})
+	handler.HandleLLMGenerateContentStart(ctx, []llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, "Hi")})
+	handler.HandleLLMGenerateContentEnd(ctx, &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: "Hello"}}})
+	handler.HandleChainEnd(ctx, map[string]any{"output": "Hello"})
+	workflow.End()
+
+	spans := spansByName(exporter)
+	if _, ok := spans["langchaingo.workflow"]; ok {
+		t.Fatalf("Expected no workflow to be started by the handler, got %v", spans)
+	}
+	chain, ok := spans["chain.task"]
+	if !ok {
+		t.Fatalf("Expected the chain to be a task of the workflow, got %v", spans)
+	}
+	if chain.Parent.SpanID() != spans["support.workflow"].SpanContext.SpanID() {
+		t.Errorf("Expected the chain span to be a child of the workflow span")
+	}
+	if spans["langchaingo.chat"].Parent.SpanID() != chain.SpanContext.SpanID() {
+		t.Errorf("Expected the LLM span to be a child of the chain span")
+	}
+
+	tracelooptest.AssertAttributes(t, tracelooptest.Attributes(chain), map[string]interface{}{
+		"traceloop.workflow.name": "support",
+	})
+	tracelooptest.AssertAttributes(t, tracelooptest.Attributes(spans["langchaingo.chat"]), map[string]interface{}{
+		"traceloop.workflow.name": "support",
+	})
+}
+
+func TestConcurrentPipelines(t *testing.T) {
+	_, tl, exporter := tracelooptest.NewServer(t, nil)
+	handler := NewHandler(tl)
+
+	first := tl.NewWorkflow(context.Background(), sdk.WorkflowAttributes{Name: "first"})
+	second := tl.NewWorkflow(context.Background(), sdk.WorkflowAttributes{Name: "second"})
+
+	// The callbacks of both pipelines interleave, as when they run in
+	// separate goroutines.
+	handler.HandleChainStart(first.Context(), map[string]any{"input": "1"})
+	handler.HandleChainStart(second.Context(), map[string]any{"input": "2"})
+	handler.HandleChainEnd(first.Context(), map[string]any{"output": "1"})
+	handler.HandleChainEnd(second.Context(), map[string]any{"output": "2"})
+	first.End()
+	second.End()
+
+	parents := make(map[trace.SpanID]string)
+	for _, span := range exporter.GetSpans() {
+		if span.Name == "first.workflow" || span.Name == "second.workflow" {
+			parents[span.SpanContext.SpanID()] = span.Name
+		}
+	}
+	chainSpans := 0
+	for _, span := range exporter.GetSpans() {
+		if span.Name != "chain.task" {
+			continue
+		}
+		chainSpans++
+
+		attrs := tracelooptest.Attributes(span)
+		expected := "first.workflow"
+		if attrs["traceloop.entity.output"] == `{"output":"2"}` {
+			expected = "second.workflow"
+		}
+		if parents[span.Parent.SpanID()] != expected {
+			t.Errorf("Expected the chain with output %v to be a child of %s, got %s", attrs["traceloop.entity.output"], expected, parents[span.Parent.SpanID()])
+		}
+		tracelooptest.AssertAttributes(t, attrs, map[string]interface{}{
+			"traceloop.workflow.name": strings.TrimSuffix(expected, ".workflow"),
+		})
+	}
+	if chainSpans != 2 {
+		t.Errorf("Expected 2 chain spans, got %d", chainSpans)
+	}
+}
@@ -0,0 +1,179 @@
+// Package otelollama instruments the github.com/ollama/ollama/api client for
+// models served locally by Ollama.
+//
+// Wrap the client with NewClient and call it as before: Chat, Generate and
+// Embed are logged as LLM spans through the Traceloop SDK, whether the
+// response is streamed to the callback or returned in a single call.
+package otelollama
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ollama/ollama/api"
+	sdk "github.com/traceloop/go-openllmetry/traceloop-sdk"
+)
+
+const vendor = "ollama"
+
+type Client struct {
+	*api.Client
+	traceloop     *sdk.Traceloop
+	workflowAttrs sdk.WorkflowAttributes
+}
+
+type Option func(*Client)
+
+// WithWorkflowAttributes sets the workflow name and association properties
+// recorded on every LLM span created by the client.
+func WithWorkflowAttributes(attrs sdk.WorkflowAttributes) Option {
+	return func(c *Client) {
+		c.workflowAttrs = attrs
+	}
+}
+
+func NewClient(traceloop *sdk.Traceloop, client *api.Client, opts ...Option) *Client {
+	c := &Client{
+		Client:    client,
+		traceloop: traceloop,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Chat logs the conversation once the last response chunk has been passed
+// to fn. Streamed responses are assembled into a single message, recording
+// the time to first token.
+func (c *Client) Chat(ctx context.Context, req *api.ChatRequest, fn api.ChatResponseFunc) error {
+	llmSpan, err := c.traceloop.LogPrompt(ctx, chatPrompt(req), c.workflowAttrs)
+	if err != nil {
+		return c.Client.Chat(ctx, req, fn)
+	}
+
+	var stream *sdk.CompletionStream
+	if streaming(req.Stream) {
+		stream = llmSpan.NewStream(ctx)
+	}
+
+	var last api.ChatResponse
+	var toolCalls int
+	err = c.Client.Chat(ctx, req, func(resp api.ChatResponse) error {
+		last = resp
+		if stream != nil {
+			stream.AddChunk(chatChunk(resp, toolCalls))
+			toolCalls += len(resp.Message.ToolCalls)
+		}
+		return fn(resp)
+	})
+	if err != nil {
+		logError(ctx, llmSpan, stream, err)
+		return err
+	}
+
+	llmSpan.SetAttributes(metricsAttributes(last.Metrics)...)
+	if stream != nil {
+		stream.Close()
+		return nil
+	}
+
+	llmSpan.LogCompletion(ctx, chatCompletion(req.Model, last), usage(last.Metrics))
+	return nil
+}
+
+// Generate logs the prompt and the generated text once the last response
+// chunk has been passed to fn.
+func (c *Client) Generate(ctx context.Context, req *api.GenerateRequest, fn api.GenerateResponseFunc) error {
+	llmSpan, err := c.traceloop.LogPrompt(ctx, generatePrompt(req), c.workflowAttrs)
+	if err != nil {
+		return c.Client.Generate(ctx, req, fn)
+	}
+
+	var stream *sdk.CompletionStream
+	if streaming(req.Stream) {
+		stream = llmSpan.NewStream(ctx)
+	}
+
+	var last api.GenerateResponse
+	err = c.Client.Generate(ctx, req, func(resp api.GenerateResponse) error {
+		last = resp
+		if stream != nil {
+			stream.AddChunk(generateChunk(resp))
+		}
+		return fn(resp)
+	})
+	if err != nil {
+		logError(ctx, llmSpan, stream, err)
+		return err
+	}
+
+	llmSpan.SetAttributes(metricsAttributes(last.Metrics)...)
+	if stream != nil {
+		stream.Close()
+		return nil
+	}
+
+	llmSpan.LogCompletion(ctx, generateCompletion(req.Model, last), usage(last.Metrics))
+	return nil
+}
+
+// streaming reports whether a request streams its response, which Ollama
+// does unless told otherwise.
+func streaming(stream *bool) bool {
+	return stream == nil || *stream
+}
+
+func (c *Client) Embed(ctx context.Context, req *api.EmbedRequest) (*api.EmbedResponse, error) {
+	llmSpan, err := c.traceloop.LogEmbeddingPrompt(ctx, sdk.EmbeddingPrompt{
+		Vendor:     vendor,
+		Model:      req.Model,
+		Inputs:     embedInputs(req.Input),
+		Dimensions: req.Dimensions,
+	}, c.workflowAttrs)
+	if err != nil {
+		return c.Client.Embed(ctx, req)
+	}
+
+	resp, err := c.Client.Embed(ctx, req)
+	if err != nil {
+		logError(ctx, llmSpan, nil, err)
+		return resp, err
+	}
+
+	metrics := api.Metrics{
+		TotalDuration:   resp.TotalDuration,
+		LoadDuration:    resp.LoadDuration,
+		PromptEvalCount: resp.PromptEvalCount,
+	}
+	llmSpan.SetAttributes(metricsAttributes(metrics)...)
+	llmSpan.LogEmbeddings(ctx, embeddings(resp), usage(metrics))
+	return resp, nil
+}
+
+// logError ends an LLM span whose request failed, closing its stream if
+// the response was streamed.
+func logError(ctx context.Context, llmSpan sdk.LLMSpan, stream *sdk.CompletionStream, err error) {
+	if stream != nil {
+		stream.CloseWithError(err, errorOptions(err)...)
+		return
+	}
+
+	llmSpan.LogError(ctx, err, errorOptions(err)...)
+}
+
+// errorOptions passes on the status code of Ollama API errors.
+func errorOptions(err error) []sdk.ErrorOption {
+	var statusErr api.StatusError
+	var authErr api.AuthorizationError
+	switch {
+	case errors.As(err, &statusErr):
+		return []sdk.ErrorOption{sdk.WithStatusCode(statusErr.StatusCode)}
+	case errors.As(err, &authErr):
+		return []sdk.ErrorOption{sdk.WithStatusCode(authErr.StatusCode)}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,210 @@
+package otelollama
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/ollama/ollama/api"
+	"github.com/traceloop/go-openllmetry/traceloop-sdk/tracelooptest"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) (*Client, *tracetest.InMemoryExporter) {
+	t.Helper()
+
+	server, tl, exporter := tracelooptest.NewServer(t, handler)
+
+	base, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse server URL: %v", err)
+	}
+
+	return NewClient(tl, api.NewClient(base, server.Client())), exporter
+}
+
+func TestChatStreaming(t *testing.T) {
+	client, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		io.WriteString(w, `{"model":"llama3.2","message":{"role":"assistant","content":"Hello"},"done":false}`+"\n")
+		io.WriteString(w, `{"model":"llama3.2","message":{"role":"assistant","content":" there"},"done":false}`+"\n")
+		io.WriteString(w, `{"model":"llama3.2","message":{"role":"assistant","content":""},"done":true,"done_reason":"stop",`+
+			`"total_duration":2500000000,"load_duration":1500000000,"prompt_eval_count":14,"prompt_eval_duration":200000000,"eval_count":3,"eval_duration":600000000}`+"\n")
+	})
+
+	var chunks int
+	err := client.Chat(context.Background(), &api.ChatRequest{
+		Model: "llama3.2",
+		Messages: []api.Message{
+			{Role: "system", Content: "You are friendly."},
+			{Role: "user", Content: "Say hello"},
+		},
+		Format:  json.RawMessage(`"json"`),
+		Options: map[string]any{"temperature": 0.7, "stop": []any{"\n\n"}, "num_predict": 64, "seed": 3},
+	}, func(resp api.ChatResponse) error {
+		chunks++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+	if chunks != 3 {
+		t.Errorf("Expected the callback to receive 3 chunks, got %d", chunks)
+	}
+
+	tracelooptest.AssertAttributes(t, tracelooptest.SpanAttributes(t, exporter), map[string]interface{}{
+		"llm.vendor":                      "ollama",
+		"llm.request.type":                "chat",
+		"llm.request.model":               "llama3.2",
+		"llm.response.model":              "llama3.2",
+		"llm.prompts.0.role":              "system",
+		"llm.prompts.1.content":           "Say hello",
+		"llm.temperature":                 float64(float32(0.7)),
+		"llm.request.max_tokens":          int64(64),
+		"llm.request.seed":                int64(3),
+		"llm.request.response_format":     "json",
+		"llm.completions.0.role":          "assistant",
+		"llm.completions.0.content":       "Hello there",
+		"llm.completions.0.finish_reason": "stop",
+		"llm.usage.prompt_tokens":         int64(14),
+		"llm.usage.completion_tokens":     int64(3),
+		"llm.usage.total_tokens":          int64(17),
+		"llm.ollama.total_duration":       2.5,
+		"llm.ollama.load_duration":        1.5,
+		"llm.ollama.eval_duration":        0.6,
+		"llm.is_streaming":                true,
+	})
+}
+
+func TestChatToolCalls(t *testing.T) {
+	client, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"model":"qwen3","message":{"role":"assistant","content":"",`+
+			`"tool_calls":[{"function":{"index":0,"name":"get_weather","arguments":{"city":"Paris"}}}]},`+
+			`"done":true,"done_reason":"stop","prompt_eval_count":40,"eval_count":12}`)
+	})
+
+	stream := false
+	err := client.Chat(context.Background(), &api.ChatRequest{
+		Model:    "qwen3",
+		Stream:   &stream,
+		Messages: []api.Message{{Role: "user", Content: "Weather in Paris?"}},
+		Tools: api.Tools{{
+			Type: "function",
+			Function: api.ToolFunction{
+				Name:        "get_weather",
+				Description: "Get the current weather",
+			},
+		}},
+	}, func(resp api.ChatResponse) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+
+	tracelooptest.AssertAttributes(t, tracelooptest.SpanAttributes(t, exporter), map[string]interface{}{
+		"llm.request.functions.0.name":             "get_weather",
+		"llm.completions.0.tool_calls.0.name":      "get_weather",
+		"llm.completions.0.tool_calls.0.arguments": `{"city":"Paris"}`,
+		"llm.usage.total_tokens":                   int64(52),
+	})
+	if _, ok := tracelooptest.SpanAttributes(t, exporter)["llm.is_streaming"]; ok {
+		t.Error("Expected a call with streaming disabled not to be recorded as streamed")
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	client, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/generate" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		io.WriteString(w, `{"model":"llama3.2","response":"The sky","done":false}`+"\n")
+		io.WriteString(w, `{"model":"llama3.2","response":" is blue.","done":true,"done_reason":"stop","prompt_eval_count":8,"eval_count":5}`+"\n")
+	})
+
+	err := client.Generate(context.Background(), &api.GenerateRequest{
+		Model:  "llama3.2",
+		System: "Answer briefly.",
+		Prompt: "Why is the sky blue?",
+		Images: []api.ImageData{api.ImageData("not really a png")},
+	}, func(resp api.GenerateResponse) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	tracelooptest.AssertAttributes(t, tracelooptest.SpanAttributes(t, exporter), map[string]interface{}{
+		"llm.request.type":                     "completion",
+		"llm.prompts.0.content":                "Answer briefly.",
+		"llm.prompts.1.content":                "Why is the sky blue?",
+		"llm.prompts.1.content_parts.1.type":   "image",
+		"llm.prompts.1.content_parts.1.sha256": "e90137d39de304eefbbe788bc535c7e82f27abbf8069505fbbd8a9dcdc4f2024",
+		"llm.completions.0.content":            "The sky is blue.",
+		"llm.completions.0.finish_reason":      "stop",
+		"llm.usage.prompt_tokens":              int64(8),
+		"llm.usage.completion_tokens":          int64(5),
+	})
+}
+
+func TestEmbed(t *testing.T) {
+	client, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"model":"nomic-embed-text","embeddings":[[0.1,0.2],[0.3,0.4]],"load_duration":250000000,"prompt_eval_count":6}`)
+	})
+
+	_, err := client.Embed(context.Background(), &api.EmbedRequest{
+		Model: "nomic-embed-text",
+		Input: []string{"first", "second"},
+	})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+
+	tracelooptest.AssertAttributes(t, tracelooptest.SpanAttributes(t, exporter), map[string]interface{}{
+		"llm.request.type":                  "embedding",
+		"llm.prompts.0.content":             "first",
+		"llm.prompts.1.content":             "second",
+		"llm.usage.prompt_tokens":           int64(6),
+		"llm.ollama.load_duration":          0.25,
+		"llm.response.embedding.count":      int64(2),
+		"llm.response.embedding.dimensions": int64(2),
+	})
+}
+
+func TestChatError(t *testing.T) {
+	client, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, `{"error":"model \"missing\" not found, try pulling it first"}`)
+	})
+
+	err := client.Chat(context.Background(), &api.ChatRequest{
+		Model:    "missing",
+		Messages: []api.Message{{Role: "user", Content: "Hi"}},
+	}, func(resp api.ChatResponse) error {
+		return nil
+	})
+	if err == nil {
+		t.Fatal("Expected Chat to fail")
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected the failed call's span to be ended, got %d spans", len(spans))
+	}
+	if spans[0].Status.Code != codes.Error {
+		t.Errorf("Expected the span status to be an error, got %v", spans[0].Status)
+	}
+	tracelooptest.AssertAttributes(t, tracelooptest.SpanAttributes(t, exporter), map[string]interface{}{
+		"llm.error.type": "other",
+	})
+}
@@ -0,0 +1,322 @@
+package otelollama
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ollama/ollama/api"
+	semconvai "github.com/traceloop/go-openllmetry/semconv-ai"
+	sdk "github.com/traceloop/go-openllmetry/traceloop-sdk"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func chatPrompt(req *api.ChatRequest) sdk.Prompt {
+	prompt := optionsPrompt(req.Options)
+	prompt.Mode = "chat"
+	prompt.Model = req.Model
+	prompt.ResponseFormat = responseFormat(req.Format)
+
+	for i, message := range req.Messages {
+		prompt.Messages = append(prompt.Messages, chatMessage(i, message))
+	}
+
+	for _, tool := range req.Tools {
+		var parameters interface{}
+		if tool.Function.Parameters.Type != "" || tool.Function.Parameters.Properties != nil {
+			parameters = tool.Function.Parameters
+		}
+		prompt.Tools = append(prompt.Tools, sdk.Tool{
+			Type: tool.Type,
+			Function: sdk.ToolFunction{
+				Name:        tool.Function.Name,
+				Description: tool.Function.Description,
+				Parameters:  parameters,
+			},
+		})
+	}
+
+	return prompt
+}
+
+func chatMessage(index int, message api.Message) sdk.Message {
+	var toolCalls []sdk.ToolCall
+	for _, toolCall := range message.ToolCalls {
+		arguments, err := json.Marshal(toolCall.Function.Arguments)
+		if err != nil {
+			fmt.Printf("Failed to marshal tool call arguments for %s: %v\n", toolCall.Function.Name, err)
+		}
+		toolCalls = append(toolCalls, sdk.ToolCall{
+			ID:   toolCall.ID,
+			Type: "function",
+			Function: sdk.ToolCallFunction{
+				Name:      toolCall.Function.Name,
+				Arguments: string(arguments),
+			},
+		})
+	}
+
+	return sdk.Message{
+		Index:        index,
+		Role:         message.Role,
+		Content:      message.Content,
+		ContentParts: imageParts(message.Content, message.Images),
+		ToolCalls:    toolCalls,
+		ToolCallID:   message.ToolCallID,
+	}
+}
+
+// imageParts converts a message carrying images into content parts, the text
+// first. Text-only messages have no parts.
+func imageParts(text string, images []api.ImageData) []sdk.ContentPart {
+	if len(images) == 0 {
+		return nil
+	}
+
+	var parts []sdk.ContentPart
+	if text != "" {
+		parts = append(parts, sdk.ContentPart{Type: sdk.ContentPartText, Text: text})
+	}
+	for _, image := range images {
+		parts = append(parts, sdk.ContentPart{Type: sdk.ContentPartImage, Data: image})
+	}
+
+	return parts
+}
+
+func generatePrompt(req *api.GenerateRequest) sdk.Prompt {
+	prompt := optionsPrompt(req.Options)
+	prompt.Mode = "completion"
+	prompt.Model = req.Model
+	prompt.ResponseFormat = responseFormat(req.Format)
+
+	if req.System != "" {
+		prompt.Messages = append(prompt.Messages, sdk.Message{
+			Index:   0,
+			Role:    "system",
+			Content: req.System,
+		})
+	}
+	prompt.Messages = append(prompt.Messages, sdk.Message{
+		Index:        len(prompt.Messages),
+		Role:         "user",
+		Content:      req.Prompt,
+		ContentParts: imageParts(req.Prompt, req.Images),
+	})
+
+	return prompt
+}
+
+// optionsPrompt reads the sampling parameters from the untyped model
+// options of a request.
+func optionsPrompt(options map[string]any) sdk.Prompt {
+	prompt := sdk.Prompt{
+		Vendor:           vendor,
+		Temperature:      floatOption(options, "temperature"),
+		TopP:             floatOption(options, "top_p"),
+		FrequencyPenalty: floatOption(options, "frequency_penalty"),
+		PresencePenalty:  floatOption(options, "presence_penalty"),
+		Seed:             intOption(options, "seed"),
+	}
+	if maxTokens := intOption(options, "num_predict"); maxTokens != nil {
+		prompt.MaxTokens = *maxTokens
+	}
+
+	switch stop := options["stop"].(type) {
+	case []string:
+		prompt.Stop = stop
+	case []any:
+		for _, s := range stop {
+			if s, ok := s.(string); ok {
+				prompt.Stop = append(prompt.Stop, s)
+			}
+		}
+	}
+
+	return prompt
+}
+
+// floatOption returns the option set under key, or nil when it is not set.
+func floatOption(options map[string]any, key string) *float32 {
+	var option float32
+	switch value := options[key].(type) {
+	case float64:
+		option = float32(value)
+	case float32:
+		option = value
+	case int:
+		option = float32(value)
+	default:
+		return nil
+	}
+
+	return &option
+}
+
+// intOption returns the option set under key, or nil when it is not set.
+func intOption(options map[string]any, key string) *int {
+	var option int
+	switch value := options[key].(type) {
+	case int:
+		option = value
+	case int64:
+		option = int(value)
+	case float64:
+		option = int(value)
+	default:
+		return nil
+	}
+
+	return &option
+}
+
+// responseFormat reads the format of a request, which is either "json" or a
+// JSON schema the response must follow.
+func responseFormat(format json.RawMessage) string {
+	var name string
+	if err := json.Unmarshal(format, &name); err == nil {
+		return name
+	}
+	if len(format) > 0 && format[0] == '{' {
+		return "json_schema"
+	}
+
+	return ""
+}
+
+// embedInputs lists the inputs of an embed request, which is either a
+// single string or a list of strings.
+func embedInputs(input any) []string {
+	var texts []string
+	switch input := input.(type) {
+	case string:
+		texts = []string{input}
+	case []string:
+		texts = input
+	case []any:
+		for _, text := range input {
+			if text, ok := text.(string); ok {
+				texts = append(texts, text)
+			}
+		}
+	}
+
+	return texts
+}
+
+func embeddings(resp *api.EmbedResponse) sdk.Embeddings {
+	result := sdk.Embeddings{
+		Model: resp.Model,
+		Count: len(resp.Embeddings),
+	}
+	if len(resp.Embeddings) > 0 {
+		result.Dimensions = len(resp.Embeddings[0])
+	}
+
+	return result
+}
+
+func usage(metrics api.Metrics) sdk.Usage {
+	return sdk.Usage{
+		TotalTokens:      metrics.PromptEvalCount + metrics.EvalCount,
+		CompletionTokens: metrics.EvalCount,
+		PromptTokens:     metrics.PromptEvalCount,
+	}
+}
+
+// metricsAttributes records how long Ollama took to load the model and to
+// evaluate the prompt and the response, in seconds.
+func metricsAttributes(metrics api.Metrics) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	for _, duration := range []struct {
+		key   attribute.Key
+		value time.Duration
+	}{
+		{semconvai.LLMOllamaTotalDuration, metrics.TotalDuration},
+		{semconvai.LLMOllamaLoadDuration, metrics.LoadDuration},
+		{semconvai.LLMOllamaPromptEvalDuration, metrics.PromptEvalDuration},
+		{semconvai.LLMOllamaEvalDuration, metrics.EvalDuration},
+	} {
+		if duration.value > 0 {
+			attrs = append(attrs, duration.key.Float64(duration.value.Seconds()))
+		}
+	}
+
+	return attrs
+}
+
+// chatChunk converts a chat response streamed to the callback. Tool calls
+// are streamed whole, so they are numbered after the toolCalls received
+// before. The final response carries the done reason and the metrics.
+func chatChunk(resp api.ChatResponse, toolCalls int) sdk.StreamChunk {
+	message := chatMessage(0, resp.Message)
+	chunk := sdk.StreamChunk{
+		Model:        resp.Model,
+		Role:         message.Role,
+		Content:      message.Content,
+		FinishReason: resp.DoneReason,
+	}
+	for i, toolCall := range message.ToolCalls {
+		chunk.ToolCalls = append(chunk.ToolCalls, sdk.ToolCallDelta{
+			Index:     toolCalls + i,
+			ID:        toolCall.ID,
+			Type:      toolCall.Type,
+			Name:      toolCall.Function.Name,
+			Arguments: toolCall.Function.Arguments,
+		})
+	}
+	if resp.Done {
+		u := usage(resp.Metrics)
+		chunk.Usage = &u
+	}
+
+	return chunk
+}
+
+func chatCompletion(model string, resp api.ChatResponse) sdk.Completion {
+	message := chatMessage(0, resp.Message)
+	message.FinishReason = resp.DoneReason
+	if message.Role == "" {
+		message.Role = "assistant"
+	}
+	if resp.Model != "" {
+		model = resp.Model
+	}
+
+	return sdk.Completion{
+		Model:    model,
+		Messages: []sdk.Message{message},
+	}
+}
+
+// generateChunk converts a generate response streamed to the callback. The
+// final response carries the done reason and the metrics.
+func generateChunk(resp api.GenerateResponse) sdk.StreamChunk {
+	chunk := sdk.StreamChunk{
+		Model:        resp.Model,
+		Content:      resp.Response,
+		FinishReason: resp.DoneReason,
+	}
+	if resp.Done {
+		u := usage(resp.Metrics)
+		chunk.Usage = &u
+	}
+
+	return chunk
+}
+
+func generateCompletion(model string, resp api.GenerateResponse) sdk.Completion {
+	if resp.Model != "" {
+		model = resp.Model
+	}
+
+	return sdk.Completion{
+		Model: model,
+		Messages: []sdk.Message{{
+			Index:        0,
+			Role:         "assistant",
+			Content:      resp.Response,
+			FinishReason: resp.DoneReason,
+		}},
+	}
+}
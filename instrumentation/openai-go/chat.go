@@ -0,0 +1,274 @@
+package otelopenai
+
+import (
+	"encoding/json"
+
+	sdk "github.com/traceloop/go-openllmetry/traceloop-sdk"
+	"github.com/traceloop/go-openllmetry/traceloop-sdk/llmhttp"
+)
+
+type chatRequest struct {
+	Model               string             `json:"model"`
+	Messages            []chatMessage      `json:"messages"`
+	Tools               []chatTool         `json:"tools"`
+	Temperature         *float32           `json:"temperature"`
+	TopP                *float32           `json:"top_p"`
+	Stop                json.RawMessage    `json:"stop"`
+	FrequencyPenalty    *float32           `json:"frequency_penalty"`
+	PresencePenalty     *float32           `json:"presence_penalty"`
+	MaxTokens           int                `json:"max_tokens"`
+	MaxCompletionTokens int                `json:"max_completion_tokens"`
+	Seed                *int               `json:"seed"`
+	N                   int                `json:"n"`
+	ResponseFormat      chatResponseFormat `json:"response_format"`
+	ToolChoice          json.RawMessage    `json:"tool_choice"`
+	User                string             `json:"user"`
+	LogitBias           map[string]int     `json:"logit_bias"`
+}
+
+type chatResponseFormat struct {
+	Type string `json:"type"`
+}
+
+type chatMessage struct {
+	Role       string          `json:"role"`
+	Content    json.RawMessage `json:"content"`
+	ToolCalls  []chatToolCall  `json:"tool_calls"`
+	ToolCallID string          `json:"tool_call_id"`
+}
+
+type chatTool struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name        string      `json:"name"`
+		Description string      `json:"description"`
+		Parameters  interface{} `json:"parameters"`
+	} `json:"function"`
+}
+
+type chatToolCall struct {
+	Index    *int   `json:"index"`
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+type chatUsage struct {
+	PromptTokens        int `json:"prompt_tokens"`
+	CompletionTokens    int `json:"completion_tokens"`
+	TotalTokens         int `json:"total_tokens"`
+	PromptTokensDetails struct {
+		CachedTokens int `json:"cached_tokens"`
+		AudioTokens  int `json:"audio_tokens"`
+	} `json:"prompt_tokens_details"`
+	CompletionTokensDetails struct {
+		ReasoningTokens int `json:"reasoning_tokens"`
+		AudioTokens     int `json:"audio_tokens"`
+	} `json:"completion_tokens_details"`
+}
+
+type chatResponse struct {
+	ID      string `json:"id"`
+	Model   string `json:"model"`
+	Choices []struct {
+		Index        int         `json:"index"`
+		Message      chatMessage `json:"message"`
+		FinishReason string      `json:"finish_reason"`
+	} `json:"choices"`
+	Usage chatUsage `json:"usage"`
+}
+
+type chatChunk struct {
+	ID      string `json:"id"`
+	Model   string `json:"model"`
+	Choices []struct {
+		Index int `json:"index"`
+		Delta struct {
+			Role      string         `json:"role"`
+			Content   string         `json:"content"`
+			ToolCalls []chatToolCall `json:"tool_calls"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *chatUsage `json:"usage"`
+}
+
+type chatEndpoint struct{}
+
+func (chatEndpoint) Prompt(body []byte) (sdk.Prompt, error) {
+	var request chatRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		return sdk.Prompt{}, err
+	}
+
+	var messages []sdk.Message
+	for i, message := range request.Messages {
+		messages = append(messages, message.toMessage(i))
+	}
+
+	var tools []sdk.Tool
+	for _, tool := range request.Tools {
+		tools = append(tools, sdk.Tool{
+			Type: tool.Type,
+			Function: sdk.ToolFunction{
+				Name:        tool.Function.Name,
+				Description: tool.Function.Description,
+				Parameters:  tool.Function.Parameters,
+			},
+		})
+	}
+
+	maxTokens := request.MaxCompletionTokens
+	if maxTokens == 0 {
+		maxTokens = request.MaxTokens
+	}
+
+	return sdk.Prompt{
+		Vendor:           vendor,
+		Mode:             "chat",
+		Model:            request.Model,
+		Temperature:      request.Temperature,
+		TopP:             request.TopP,
+		Stop:             stringList(request.Stop),
+		FrequencyPenalty: request.FrequencyPenalty,
+		PresencePenalty:  request.PresencePenalty,
+		MaxTokens:        maxTokens,
+		Seed:             request.Seed,
+		N:                request.N,
+		ResponseFormat:   request.ResponseFormat.Type,
+		ToolChoice:       toolChoice(request.ToolChoice),
+		User:             request.User,
+		LogitBias:        request.LogitBias,
+		Messages:         messages,
+		Tools:            tools,
+	}, nil
+}
+
+func (chatEndpoint) Completion(body []byte) (sdk.Completion, sdk.Usage, error) {
+	var response chatResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return sdk.Completion{}, sdk.Usage{}, err
+	}
+
+	var messages []sdk.Message
+	for _, choice := range response.Choices {
+		message := choice.Message.toMessage(choice.Index)
+		message.FinishReason = choice.FinishReason
+		messages = append(messages, message)
+	}
+
+	return sdk.Completion{
+		ID:       response.ID,
+		Model:    response.Model,
+		Messages: messages,
+	}, response.Usage.toUsage(), nil
+}
+
+func (chatEndpoint) StreamDecoder() llmhttp.StreamDecoder {
+	return &chatDecoder{toolCalls: make(map[int]int)}
+}
+
+func (message chatMessage) toMessage(index int) sdk.Message {
+	var toolCalls []sdk.ToolCall
+	for _, toolCall := range message.ToolCalls {
+		toolCalls = append(toolCalls, toolCall.toToolCall())
+	}
+
+	return sdk.Message{
+		Index:        index,
+		Role:         message.Role,
+		Content:      textContent(message.Content),
+		ContentParts: contentParts(message.Content),
+		ToolCalls:    toolCalls,
+		ToolCallID:   message.ToolCallID,
+	}
+}
+
+func (toolCall chatToolCall) toToolCall() sdk.ToolCall {
+	return sdk.ToolCall{
+		ID:   toolCall.ID,
+		Type: toolCall.Type,
+		Function: sdk.ToolCallFunction{
+			Name:      toolCall.Function.Name,
+			Arguments: toolCall.Function.Arguments,
+		},
+	}
+}
+
+func (usage chatUsage) toUsage() sdk.Usage {
+	return sdk.Usage{
+		TotalTokens:           usage.TotalTokens,
+		CompletionTokens:      usage.CompletionTokens,
+		PromptTokens:          usage.PromptTokens,
+		CacheReadInputTokens:  usage.PromptTokensDetails.CachedTokens,
+		ReasoningTokens:       usage.CompletionTokensDetails.ReasoningTokens,
+		PromptAudioTokens:     usage.PromptTokensDetails.AudioTokens,
+		CompletionAudioTokens: usage.CompletionTokensDetails.AudioTokens,
+	}
+}
+
+// chatDecoder converts chat completion chunks, tracking the last tool call
+// of each choice for vendors that leave the index of tool call deltas unset.
+type chatDecoder struct {
+	toolCalls map[int]int
+}
+
+func (decoder *chatDecoder) Chunks(data []byte) []sdk.StreamChunk {
+	var chunk chatChunk
+	if err := json.Unmarshal(data, &chunk); err != nil {
+		return nil
+	}
+
+	var usage *sdk.Usage
+	if chunk.Usage != nil {
+		u := chunk.Usage.toUsage()
+		usage = &u
+	}
+
+	if len(chunk.Choices) == 0 {
+		if usage == nil {
+			return nil
+		}
+		return []sdk.StreamChunk{{ID: chunk.ID, Model: chunk.Model, Usage: usage}}
+	}
+
+	var chunks []sdk.StreamChunk
+	for i, choice := range chunk.Choices {
+		streamChunk := sdk.StreamChunk{
+			ID:           chunk.ID,
+			Model:        chunk.Model,
+			Index:        choice.Index,
+			Role:         choice.Delta.Role,
+			Content:      choice.Delta.Content,
+			FinishReason: choice.FinishReason,
+		}
+		if i == 0 {
+			streamChunk.Usage = usage
+		}
+
+		for _, toolCall := range choice.Delta.ToolCalls {
+			index, ok := decoder.toolCalls[choice.Index]
+			if toolCall.Index != nil {
+				index = *toolCall.Index
+			} else if toolCall.ID != "" && ok {
+				index++
+			}
+			decoder.toolCalls[choice.Index] = index
+
+			streamChunk.ToolCalls = append(streamChunk.ToolCalls, sdk.ToolCallDelta{
+				Index:     index,
+				ID:        toolCall.ID,
+				Type:      toolCall.Type,
+				Name:      toolCall.Function.Name,
+				Arguments: toolCall.Function.Arguments,
+			})
+		}
+
+		chunks = append(chunks, streamChunk)
+	}
+
+	return chunks
+}
@@ -0,0 +1,216 @@
+// Package otelopenai instruments the official github.com/openai/openai-go SDK.
+//
+// Register the middleware once on the client and every Chat Completions and
+// Responses API call is logged as an LLM span through the Traceloop SDK:
+//
+//	client := openai.NewClient(
+//		option.WithMiddleware(otelopenai.Middleware(traceloop)),
+//	)
+package otelopenai
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/openai/openai-go/option"
+	sdk "github.com/traceloop/go-openllmetry/traceloop-sdk"
+	"github.com/traceloop/go-openllmetry/traceloop-sdk/llmhttp"
+)
+
+const vendor = "openai"
+
+type config struct {
+	workflowAttrs sdk.WorkflowAttributes
+}
+
+type Option func(*config)
+
+// WithWorkflowAttributes sets the workflow name and association properties
+// recorded on every LLM span created by the middleware.
+func WithWorkflowAttributes(attrs sdk.WorkflowAttributes) Option {
+	return func(c *config) {
+		c.workflowAttrs = attrs
+	}
+}
+
+func Middleware(traceloop *sdk.Traceloop, opts ...Option) option.Middleware {
+	cfg := config{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	middleware := llmhttp.Middleware(traceloop, cfg.workflowAttrs, endpointFor)
+	return func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		return middleware(req, next)
+	}
+}
+
+func endpointFor(req *http.Request) llmhttp.Endpoint {
+	if req.Method != http.MethodPost {
+		return nil
+	}
+
+	path := strings.TrimSuffix(req.URL.Path, "/")
+	switch {
+	case strings.HasSuffix(path, "/chat/completions"):
+		return chatEndpoint{}
+	case strings.HasSuffix(path, "/responses"):
+		return responsesEndpoint{}
+	default:
+		return nil
+	}
+}
+
+// textContent flattens message content, which is either a plain string or an
+// array of typed parts, into its text.
+func textContent(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+
+	var parts []struct {
+		Text    string `json:"text"`
+		Refusal string `json:"refusal"`
+	}
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return ""
+	}
+
+	var texts []string
+	for _, part := range parts {
+		if part.Text != "" {
+			texts = append(texts, part.Text)
+		} else if part.Refusal != "" {
+			texts = append(texts, part.Refusal)
+		}
+	}
+
+	return strings.Join(texts, "\n")
+}
+
+// contentPart covers the content part shapes of both the Chat Completions and
+// the Responses APIs.
+type contentPart struct {
+	Type     string          `json:"type"`
+	Text     string          `json:"text"`
+	Refusal  string          `json:"refusal"`
+	ImageURL json.RawMessage `json:"image_url"`
+	FileID   string          `json:"file_id"`
+	FileData string          `json:"file_data"`
+	Filename string          `json:"filename"`
+	File     *struct {
+		FileID   string `json:"file_id"`
+		FileData string `json:"file_data"`
+		Filename string `json:"filename"`
+	} `json:"file"`
+	InputAudio *struct {
+		Data   string `json:"data"`
+		Format string `json:"format"`
+	} `json:"input_audio"`
+}
+
+// contentParts converts message content given as an array of parts. Plain
+// string content has no parts.
+func contentParts(raw json.RawMessage) []sdk.ContentPart {
+	var parts []contentPart
+	if len(raw) == 0 || json.Unmarshal(raw, &parts) != nil {
+		return nil
+	}
+
+	var result []sdk.ContentPart
+	for _, part := range parts {
+		switch part.Type {
+		case "text", "input_text", "output_text":
+			result = append(result, sdk.ContentPart{Type: sdk.ContentPartText, Text: part.Text})
+		case "refusal":
+			result = append(result, sdk.ContentPart{Type: sdk.ContentPartText, Text: part.Refusal})
+		case "image_url", "input_image":
+			result = append(result, sdk.ContentPart{Type: sdk.ContentPartImage, URL: imageURL(part.ImageURL), FileID: part.FileID})
+		case "input_audio":
+			if part.InputAudio == nil {
+				continue
+			}
+			data, _ := base64.StdEncoding.DecodeString(part.InputAudio.Data)
+			result = append(result, sdk.ContentPart{Type: sdk.ContentPartAudio, Data: data, MimeType: "audio/" + part.InputAudio.Format})
+		case "file":
+			if part.File == nil {
+				continue
+			}
+			result = append(result, sdk.ContentPart{Type: sdk.ContentPartFile, URL: part.File.FileData, FileID: part.File.FileID, Filename: part.File.Filename})
+		case "input_file":
+			result = append(result, sdk.ContentPart{Type: sdk.ContentPartFile, URL: part.FileData, FileID: part.FileID, Filename: part.Filename})
+		}
+	}
+
+	return result
+}
+
+// imageURL decodes the image URL of a part, which the Chat Completions API
+// nests in an object and the Responses API gives as a string.
+func imageURL(raw json.RawMessage) string {
+	var url string
+	if err := json.Unmarshal(raw, &url); err == nil {
+		return url
+	}
+
+	var image struct {
+		URL string `json:"url"`
+	}
+	json.Unmarshal(raw, &image)
+
+	return image.URL
+}
+
+// stringList decodes fields that accept either a string or an array of strings.
+func stringList(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var value string
+	if err := json.Unmarshal(raw, &value); err == nil {
+		return []string{value}
+	}
+
+	var values []string
+	json.Unmarshal(raw, &values)
+	return values
+}
+
+// toolChoice reads the tool choice of a request, which is either a mode such
+// as "auto" or an object naming the function to call. Chat completions nest
+// the name under "function" while responses set it on the object itself.
+func toolChoice(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+
+	var mode string
+	if err := json.Unmarshal(raw, &mode); err == nil {
+		return mode
+	}
+
+	var choice struct {
+		Type     string `json:"type"`
+		Name     string `json:"name"`
+		Function struct {
+			Name string `json:"name"`
+		} `json:"function"`
+	}
+	json.Unmarshal(raw, &choice)
+	if choice.Function.Name != "" {
+		return choice.Function.Name
+	}
+	if choice.Name != "" {
+		return choice.Name
+	}
+
+	return choice.Type
+}
@@ -0,0 +1,300 @@
+package otelopenai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+	"github.com/traceloop/go-openllmetry/traceloop-sdk/tracelooptest"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) (*openai.Client, *tracetest.InMemoryExporter) {
+	t.Helper()
+
+	server, tl, exporter := tracelooptest.NewServer(t, handler)
+
+	client := openai.NewClient(
+		option.WithAPIKey("test-key"),
+		option.WithBaseURL(server.URL+"/v1/"),
+		option.WithMaxRetries(0),
+		option.WithMiddleware(Middleware(tl)),
+	)
+
+	return client, exporter
+}
+
+func TestChatCompletionsMiddleware(t *testing.T) {
+	client, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{
+			"id": "chatcmpl-1",
+			"object": "chat.completion",
+			"model": "gpt-4o-mini-2024-07-18",
+			"choices": [{
+				"index": 0,
+				"finish_reason": "tool_calls",
+				"message": {
+					"role": "assistant",
+					"content": null,
+					"tool_calls": [{
+						"id": "call_1",
+						"type": "function",
+						"function": {"name": "get_weather", "arguments": "{\"location\":\"Paris\"}"}
+					}]
+				}
+			}],
+			"usage": {"prompt_tokens": 82, "completion_tokens": 17, "total_tokens": 99}
+		}`)
+	})
+
+	_, err := client.Chat.Completions.New(context.Background(), openai.ChatCompletionNewParams{
+		Messages: openai.F([]openai.ChatCompletionMessageParamUnion{
+			openai.UserMessage("What's the weather like in Paris?"),
+		}),
+		Model: openai.F(openai.ChatModelGPT4oMini),
+		Tools: openai.F([]openai.ChatCompletionToolParam{{
+			Type: openai.F(openai.ChatCompletionToolTypeFunction),
+			Function: openai.F(openai.FunctionDefinitionParam{
+				Name:        openai.F("get_weather"),
+				Description: openai.F("Get the current weather for a given location"),
+			}),
+		}}),
+		ToolChoice: openai.F[openai.ChatCompletionToolChoiceOptionUnionParam](openai.ChatCompletionNamedToolChoiceParam{
+			Type:     openai.F(openai.ChatCompletionNamedToolChoiceTypeFunction),
+			Function: openai.F(openai.ChatCompletionNamedToolChoiceFunctionParam{Name: openai.F("get_weather")}),
+		}),
+		Temperature:         openai.F(0.7),
+		Seed:                openai.F(int64(42)),
+		MaxCompletionTokens: openai.F(int64(256)),
+	})
+	if err != nil {
+		t.Fatalf("Chat.Completions.New failed: %v", err)
+	}
+
+	tracelooptest.AssertAttributes(t, tracelooptest.SpanAttributes(t, exporter), map[string]interface{}{
+		"llm.vendor":                               "openai",
+		"llm.request.type":                         "chat",
+		"llm.request.model":                        "gpt-4o-mini",
+		"llm.response.id":                          "chatcmpl-1",
+		"llm.response.model":                       "gpt-4o-mini-2024-07-18",
+		"llm.prompts.0.role":                       "user",
+		"llm.prompts.0.content":                    "What's the weather like in Paris?",
+		"llm.request.functions.0.name":             "get_weather",
+		"llm.request.tool_choice":                  "get_weather",
+		"llm.request.seed":                         int64(42),
+		"llm.request.max_tokens":                   int64(256),
+		"llm.temperature":                          float64(float32(0.7)),
+		"llm.completions.0.tool_calls.0.id":        "call_1",
+		"llm.completions.0.tool_calls.0.arguments": `{"location":"Paris"}`,
+		"llm.completions.0.finish_reason":          "tool_calls",
+		"llm.usage.total_tokens":                   int64(99),
+	})
+}
+
+func TestChatCompletionsStreamingMiddleware(t *testing.T) {
+	client, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{
+			`{"id":"1","object":"chat.completion.chunk","model":"gpt-4o-mini-2024-07-18","choices":[{"index":0,"delta":{"role":"assistant","tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"get_weather","arguments":""}}]}}]}`,
+			`{"id":"1","object":"chat.completion.chunk","model":"gpt-4o-mini-2024-07-18","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"location\":"}}]}}]}`,
+			`{"id":"1","object":"chat.completion.chunk","model":"gpt-4o-mini-2024-07-18","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"Paris\"}"}}]}}]}`,
+			`{"id":"1","object":"chat.completion.chunk","model":"gpt-4o-mini-2024-07-18","choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}`,
+		} {
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+		io.WriteString(w, "data: [DONE]\n\n")
+	})
+
+	stream := client.Chat.Completions.NewStreaming(context.Background(), openai.ChatCompletionNewParams{
+		Messages: openai.F([]openai.ChatCompletionMessageParamUnion{
+			openai.UserMessage("What's the weather like in Paris?"),
+		}),
+		Model: openai.F(openai.ChatModelGPT4oMini),
+	})
+	for stream.Next() {
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatalf("NewStreaming failed: %v", err)
+	}
+	stream.Close()
+
+	tracelooptest.AssertAttributes(t, tracelooptest.SpanAttributes(t, exporter), map[string]interface{}{
+		"llm.response.id":                          "1",
+		"llm.response.model":                       "gpt-4o-mini-2024-07-18",
+		"llm.completions.0.role":                   "assistant",
+		"llm.completions.0.tool_calls.0.name":      "get_weather",
+		"llm.completions.0.tool_calls.0.arguments": `{"location":"Paris"}`,
+		"llm.completions.0.finish_reason":          "tool_calls",
+		"llm.is_streaming":                         true,
+	})
+}
+
+func TestResponsesStreamingMiddleware(t *testing.T) {
+	client, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range []string{
+			`{"type":"response.created","response":{"id":"resp_1","model":"gpt-4.1-2025-04-14","output":[]}}`,
+			`{"type":"response.output_item.added","output_index":0,"item":{"type":"message","role":"assistant","content":[]}}`,
+			`{"type":"response.output_text.delta","output_index":0,"content_index":0,"delta":"Let me "}`,
+			`{"type":"response.output_text.delta","output_index":0,"content_index":0,"delta":"check."}`,
+			`{"type":"response.output_item.added","output_index":1,"item":{"type":"function_call","call_id":"call_2","name":"get_weather","arguments":""}}`,
+			`{"type":"response.function_call_arguments.delta","output_index":1,"delta":"{\"location\":"}`,
+			`{"type":"response.function_call_arguments.delta","output_index":1,"delta":"\"Rome\"}"}`,
+			`{"type":"response.completed","response":{"id":"resp_1","model":"gpt-4.1-2025-04-14","output":[],"usage":{"input_tokens":30,"output_tokens":12,"total_tokens":42}}}`,
+		} {
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", event)
+		}
+	})
+
+	params := map[string]interface{}{
+		"model":  "gpt-4.1",
+		"input":  "Weather in Rome?",
+		"stream": true,
+	}
+	var resp *http.Response
+	if err := client.Post(context.Background(), "responses", params, &resp); err != nil {
+		t.Fatalf("Post responses failed: %v", err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	tracelooptest.AssertAttributes(t, tracelooptest.SpanAttributes(t, exporter), map[string]interface{}{
+		"llm.response.id":                          "resp_1",
+		"llm.response.model":                       "gpt-4.1-2025-04-14",
+		"llm.completions.0.content":                "Let me check.",
+		"llm.completions.0.tool_calls.0.id":        "call_2",
+		"llm.completions.0.tool_calls.0.name":      "get_weather",
+		"llm.completions.0.tool_calls.0.arguments": `{"location":"Rome"}`,
+		"llm.usage.total_tokens":                   int64(42),
+		"llm.is_streaming":                         true,
+	})
+}
+
+func TestResponsesMiddleware(t *testing.T) {
+	client, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/responses" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{
+			"id": "resp_1",
+			"model": "gpt-4.1-2025-04-14",
+			"output": [
+				{"type": "reasoning", "id": "rs_1", "summary": []},
+				{"type": "message", "role": "assistant", "content": [{"type": "output_text", "text": "Let me check."}]},
+				{"type": "function_call", "call_id": "call_2", "name": "get_weather", "arguments": "{\"location\":\"Rome\"}"}
+			],
+			"usage": {
+				"input_tokens": 30,
+				"output_tokens": 12,
+				"total_tokens": 42,
+				"input_tokens_details": {"cached_tokens": 10},
+				"output_tokens_details": {"reasoning_tokens": 7}
+			}
+		}`)
+	})
+
+	params := map[string]interface{}{
+		"model":        "gpt-4.1",
+		"instructions": "You are a weather bot.",
+		"input": []map[string]interface{}{
+			{"role": "user", "content": []map[string]string{
+				{"type": "input_text", "text": "Weather in Rome?"},
+				{"type": "input_image", "image_url": "https://example.com/rome.jpg"},
+			}},
+		},
+		"tools": []map[string]interface{}{
+			{"type": "function", "name": "get_weather", "description": "Get the current weather"},
+		},
+	}
+	var res map[string]interface{}
+	if err := client.Post(context.Background(), "responses", params, &res); err != nil {
+		t.Fatalf("Post responses failed: %v", err)
+	}
+
+	tracelooptest.AssertAttributes(t, tracelooptest.SpanAttributes(t, exporter), map[string]interface{}{
+		"llm.request.model":                   "gpt-4.1",
+		"llm.response.id":                     "resp_1",
+		"llm.response.model":                  "gpt-4.1-2025-04-14",
+		"llm.prompts.0.role":                  "system",
+		"llm.prompts.0.content":               "You are a weather bot.",
+		"llm.prompts.1.role":                  "user",
+		"llm.prompts.1.content":               "Weather in Rome?",
+		"llm.prompts.1.content_parts.1.type":  "image",
+		"llm.prompts.1.content_parts.1.url":   "https://example.com/rome.jpg",
+		"llm.request.functions.0.name":        "get_weather",
+		"llm.completions.0.content":           "Let me check.",
+		"llm.completions.0.tool_calls.0.id":   "call_2",
+		"llm.completions.0.tool_calls.0.name": "get_weather",
+		"llm.usage.prompt_tokens":             int64(30),
+		"llm.usage.completion_tokens":         int64(12),
+		"llm.usage.cache_read_input_tokens":   int64(10),
+		"llm.usage.reasoning_tokens":          int64(7),
+	})
+}
+
+func TestResponsesMiddlewareWithoutInput(t *testing.T) {
+	client, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{
+			"id": "resp_2",
+			"model": "gpt-4.1-2025-04-14",
+			"output": [{"type": "message", "role": "assistant", "content": [{"type": "output_text", "text": "Sunny."}]}],
+			"usage": {"input_tokens": 40, "output_tokens": 2, "total_tokens": 42}
+		}`)
+	})
+
+	params := map[string]interface{}{
+		"model":                "gpt-4.1",
+		"instructions":         "Answer in one word.",
+		"previous_response_id": "resp_1",
+	}
+	var res map[string]interface{}
+	if err := client.Post(context.Background(), "responses", params, &res); err != nil {
+		t.Fatalf("Post responses failed: %v", err)
+	}
+
+	attrs := tracelooptest.SpanAttributes(t, exporter)
+	tracelooptest.AssertAttributes(t, attrs, map[string]interface{}{
+		"llm.prompts.0.role":        "system",
+		"llm.prompts.0.content":     "Answer in one word.",
+		"llm.completions.0.content": "Sunny.",
+		"llm.usage.total_tokens":    int64(42),
+	})
+	if _, exists := attrs["llm.prompts.1.role"]; exists {
+		t.Error("Expected no input message")
+	}
+}
+
+func TestChatCompletionsMiddlewareError(t *testing.T) {
+	client, exporter := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		io.WriteString(w, `{"error":{"message":"Incorrect API key provided","type":"invalid_request_error","code":"invalid_api_key"}}`)
+	})
+
+	_, err := client.Chat.Completions.New(context.Background(), openai.ChatCompletionNewParams{
+		Messages: openai.F([]openai.ChatCompletionMessageParamUnion{openai.UserMessage("Hi")}),
+		Model:    openai.F(openai.ChatModelGPT4oMini),
+	})
+	if err == nil {
+		t.Fatal("Expected Chat.Completions.New to fail")
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected the failed call's span to be ended, got %d spans", len(spans))
+	}
+	if spans[0].Status.Code != codes.Error {
+		t.Errorf("Expected the span status to be an error, got %v", spans[0].Status)
+	}
+	tracelooptest.AssertAttributes(t, tracelooptest.SpanAttributes(t, exporter), map[string]interface{}{
+		"llm.error.type": "auth",
+	})
+}
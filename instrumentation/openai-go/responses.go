@@ -0,0 +1,290 @@
+package otelopenai
+
+import (
+	"encoding/json"
+	"strings"
+
+	sdk "github.com/traceloop/go-openllmetry/traceloop-sdk"
+	"github.com/traceloop/go-openllmetry/traceloop-sdk/llmhttp"
+)
+
+type responsesRequest struct {
+	Model        string          `json:"model"`
+	Instructions string          `json:"instructions"`
+	Input        json.RawMessage `json:"input"`
+	Tools        []responsesTool `json:"tools"`
+	Temperature  *float32        `json:"temperature"`
+	TopP         *float32        `json:"top_p"`
+	MaxTokens    int             `json:"max_output_tokens"`
+	Text         responsesText   `json:"text"`
+	ToolChoice   json.RawMessage `json:"tool_choice"`
+	User         string          `json:"user"`
+}
+
+// responsesText configures the output format of a response.
+type responsesText struct {
+	Format struct {
+		Type string `json:"type"`
+	} `json:"format"`
+}
+
+type responsesTool struct {
+	Type        string      `json:"type"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Parameters  interface{} `json:"parameters"`
+}
+
+// responsesItem covers the input and output item types we record: messages,
+// function calls and function call outputs.
+type responsesItem struct {
+	Type      string          `json:"type"`
+	Role      string          `json:"role"`
+	Content   json.RawMessage `json:"content"`
+	CallID    string          `json:"call_id"`
+	Name      string          `json:"name"`
+	Arguments string          `json:"arguments"`
+	Output    json.RawMessage `json:"output"`
+}
+
+type responsesResponse struct {
+	ID     string          `json:"id"`
+	Model  string          `json:"model"`
+	Output []responsesItem `json:"output"`
+	Usage  struct {
+		InputTokens        int `json:"input_tokens"`
+		OutputTokens       int `json:"output_tokens"`
+		TotalTokens        int `json:"total_tokens"`
+		InputTokensDetails struct {
+			CachedTokens int `json:"cached_tokens"`
+		} `json:"input_tokens_details"`
+		OutputTokensDetails struct {
+			ReasoningTokens int `json:"reasoning_tokens"`
+		} `json:"output_tokens_details"`
+	} `json:"usage"`
+}
+
+type responsesEndpoint struct{}
+
+func (responsesEndpoint) Prompt(body []byte) (sdk.Prompt, error) {
+	var request responsesRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		return sdk.Prompt{}, err
+	}
+
+	var messages []sdk.Message
+	if request.Instructions != "" {
+		messages = append(messages, sdk.Message{
+			Index:   0,
+			Role:    "system",
+			Content: request.Instructions,
+		})
+	}
+
+	var input string
+	switch {
+	case len(request.Input) == 0 || string(request.Input) == "null":
+		// The input is optional, e.g. when continuing a previous response or
+		// running a stored prompt.
+	case json.Unmarshal(request.Input, &input) == nil:
+		messages = append(messages, sdk.Message{
+			Index:   len(messages),
+			Role:    "user",
+			Content: input,
+		})
+	default:
+		var items []responsesItem
+		if err := json.Unmarshal(request.Input, &items); err != nil {
+			return sdk.Prompt{}, err
+		}
+		for _, item := range items {
+			messages = append(messages, item.toMessage(len(messages)))
+		}
+	}
+
+	var tools []sdk.Tool
+	for _, tool := range request.Tools {
+		name := tool.Name
+		if name == "" {
+			name = tool.Type
+		}
+		tools = append(tools, sdk.Tool{
+			Type: tool.Type,
+			Function: sdk.ToolFunction{
+				Name:        name,
+				Description: tool.Description,
+				Parameters:  tool.Parameters,
+			},
+		})
+	}
+
+	return sdk.Prompt{
+		Vendor:         vendor,
+		Mode:           "chat",
+		Model:          request.Model,
+		Temperature:    request.Temperature,
+		TopP:           request.TopP,
+		MaxTokens:      request.MaxTokens,
+		ResponseFormat: request.Text.Format.Type,
+		ToolChoice:     toolChoice(request.ToolChoice),
+		User:           request.User,
+		Messages:       messages,
+		Tools:          tools,
+	}, nil
+}
+
+func (responsesEndpoint) Completion(body []byte) (sdk.Completion, sdk.Usage, error) {
+	var response responsesResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return sdk.Completion{}, sdk.Usage{}, err
+	}
+
+	return response.toCompletion(), response.toUsage(), nil
+}
+
+func (responsesEndpoint) StreamDecoder() llmhttp.StreamDecoder {
+	return &responsesDecoder{toolCalls: make(map[int]int)}
+}
+
+func (item responsesItem) toMessage(index int) sdk.Message {
+	switch item.Type {
+	case "function_call":
+		return sdk.Message{
+			Index: index,
+			Role:  "assistant",
+			ToolCalls: []sdk.ToolCall{
+				item.toToolCall(),
+			},
+		}
+	case "function_call_output":
+		output := textContent(item.Output)
+		if output == "" {
+			output = string(item.Output)
+		}
+		return sdk.Message{
+			Index:      index,
+			Role:       "tool",
+			Content:    output,
+			ToolCallID: item.CallID,
+		}
+	default:
+		return sdk.Message{
+			Index:        index,
+			Role:         item.Role,
+			Content:      textContent(item.Content),
+			ContentParts: contentParts(item.Content),
+		}
+	}
+}
+
+func (item responsesItem) toToolCall() sdk.ToolCall {
+	return sdk.ToolCall{
+		ID:   item.CallID,
+		Type: "function",
+		Function: sdk.ToolCallFunction{
+			Name:      item.Name,
+			Arguments: item.Arguments,
+		},
+	}
+}
+
+// toCompletion folds the output items into a single assistant message, the
+// same shape a Chat Completions choice has.
+func (response responsesResponse) toCompletion() sdk.Completion {
+	message := sdk.Message{
+		Index: 0,
+		Role:  "assistant",
+	}
+
+	var texts []string
+	for _, item := range response.Output {
+		switch item.Type {
+		case "message":
+			texts = append(texts, textContent(item.Content))
+		case "function_call":
+			message.ToolCalls = append(message.ToolCalls, item.toToolCall())
+		}
+	}
+	message.Content = strings.Join(texts, "\n")
+
+	return sdk.Completion{
+		ID:       response.ID,
+		Model:    response.Model,
+		Messages: []sdk.Message{message},
+	}
+}
+
+func (response responsesResponse) toUsage() sdk.Usage {
+	return sdk.Usage{
+		TotalTokens:          response.Usage.TotalTokens,
+		CompletionTokens:     response.Usage.OutputTokens,
+		PromptTokens:         response.Usage.InputTokens,
+		CacheReadInputTokens: response.Usage.InputTokensDetails.CachedTokens,
+		ReasoningTokens:      response.Usage.OutputTokensDetails.ReasoningTokens,
+	}
+}
+
+// responsesDecoder converts the events of a streamed response into chunks
+// of a single choice, as the output items are folded into a single message.
+type responsesDecoder struct {
+	// toolCalls maps the output index of function call items to their index
+	// among the tool calls of the message.
+	toolCalls map[int]int
+	hasText   bool
+}
+
+type responsesEvent struct {
+	Type        string             `json:"type"`
+	OutputIndex int                `json:"output_index"`
+	Delta       string             `json:"delta"`
+	Item        *responsesItem     `json:"item"`
+	Response    *responsesResponse `json:"response"`
+}
+
+func (decoder *responsesDecoder) Chunks(data []byte) []sdk.StreamChunk {
+	var event responsesEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil
+	}
+
+	switch event.Type {
+	case "response.output_item.added":
+		if event.Item == nil {
+			return nil
+		}
+		switch event.Item.Type {
+		case "message":
+			// Separate the texts of successive output messages, as in the
+			// completion of an unstreamed response.
+			if decoder.hasText {
+				return []sdk.StreamChunk{{Content: "\n"}}
+			}
+		case "function_call":
+			index := len(decoder.toolCalls)
+			decoder.toolCalls[event.OutputIndex] = index
+			return []sdk.StreamChunk{{ToolCalls: []sdk.ToolCallDelta{{
+				Index: index,
+				ID:    event.Item.CallID,
+				Type:  "function",
+				Name:  event.Item.Name,
+			}}}}
+		}
+	case "response.output_text.delta", "response.refusal.delta":
+		decoder.hasText = true
+		return []sdk.StreamChunk{{Content: event.Delta}}
+	case "response.function_call_arguments.delta":
+		index, ok := decoder.toolCalls[event.OutputIndex]
+		if !ok {
+			return nil
+		}
+		return []sdk.StreamChunk{{ToolCalls: []sdk.ToolCallDelta{{Index: index, Arguments: event.Delta}}}}
+	case "response.completed", "response.incomplete", "response.failed":
+		if event.Response == nil {
+			return nil
+		}
+		usage := event.Response.toUsage()
+		return []sdk.StreamChunk{{ID: event.Response.ID, Model: event.Response.Model, Usage: &usage}}
+	}
+
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
+	otelopenai "github.com/traceloop/go-openllmetry/instrumentation/openai-go"
 	sdk "github.com/traceloop/go-openllmetry/traceloop-sdk"
 )
 
@@ -36,66 +37,20 @@ func runToolCallingExample() {
 
 	client := openai.NewClient(
 		option.WithAPIKey(os.Getenv("OPENAI_API_KEY")),
+		option.WithMiddleware(otelopenai.Middleware(
+			traceloop,
+			otelopenai.WithWorkflowAttributes(sdk.WorkflowAttributes{
+				Name: "tool-calling-example",
+				AssociationProperties: map[string]string{
+					"user_id": "demo-user",
+				},
+			}),
+		)),
 	)
 
 	userPrompt := "What's the weather like in San Francisco?"
-	
-	// Define tools
-	tools := []sdk.Tool{
-		{
-			Type: "function",
-			Function: sdk.ToolFunction{
-				Name:        "get_weather",
-				Description: "Get the current weather for a given location",
-				Parameters: map[string]interface{}{
-					"type": "object",
-					"properties": map[string]interface{}{
-						"location": map[string]interface{}{
-							"type":        "string",
-							"description": "The city and state, e.g. San Francisco, CA",
-						},
-						"unit": map[string]interface{}{
-							"type":        "string",
-							"enum":        []string{"celsius", "fahrenheit"},
-							"description": "The unit of temperature",
-						},
-					},
-					"required": []string{"location"},
-				},
-			},
-		},
-	}
-
-	// Create prompt
-	prompt := sdk.Prompt{
-		Vendor: "openai",
-		Mode:   "chat",
-		Model:  "gpt-4o-mini",
-		Messages: []sdk.Message{
-			{
-				Index:   0,
-				Role:    "user",
-				Content: userPrompt,
-			},
-		},
-		Tools: tools,
-	}
-
-	workflowAttrs := sdk.WorkflowAttributes{
-		Name: "tool-calling-example",
-		AssociationProperties: map[string]string{
-			"user_id": "demo-user",
-		},
-	}
 
 	fmt.Printf("User: %s\n", userPrompt)
-	
-	// Log the prompt
-	llmSpan, err := traceloop.LogPrompt(ctx, prompt, workflowAttrs)
-	if err != nil {
-		fmt.Printf("Error logging prompt: %v\n", err)
-		return
-	}
 
 	// Make API call to OpenAI
 	startTime := time.Now()
@@ -138,49 +93,6 @@ func runToolCallingExample() {
 
 	fmt.Printf("\nAssistant: %s\n", resp.Choices[0].Message.Content)
 
-	// Convert response to our format
-	var completionMessages []sdk.Message
-	for _, choice := range resp.Choices {
-		message := sdk.Message{
-			Index:   int(choice.Index),
-			Role:    string(choice.Message.Role),
-			Content: choice.Message.Content,
-		}
-		
-		// Convert tool calls if present
-		if len(choice.Message.ToolCalls) > 0 {
-			for _, toolCall := range choice.Message.ToolCalls {
-				message.ToolCalls = append(message.ToolCalls, sdk.ToolCall{
-					ID:   toolCall.ID,
-					Type: string(toolCall.Type),
-					Function: sdk.ToolCallFunction{
-						Name:      toolCall.Function.Name,
-						Arguments: toolCall.Function.Arguments,
-					},
-				})
-			}
-		}
-		completionMessages = append(completionMessages, message)
-	}
-
-	// Log the completion
-	completion := sdk.Completion{
-		Model:    resp.Model,
-		Messages: completionMessages,
-	}
-
-	usage := sdk.Usage{
-		TotalTokens:      int(resp.Usage.TotalTokens),
-		CompletionTokens: int(resp.Usage.CompletionTokens),
-		PromptTokens:     int(resp.Usage.PromptTokens),
-	}
-
-	err = llmSpan.LogCompletion(ctx, completion, usage)
-	if err != nil {
-		fmt.Printf("Error logging completion: %v\n", err)
-		return
-	}
-
 	// If tool calls were made, execute them
 	if len(resp.Choices[0].Message.ToolCalls) > 0 {
 		fmt.Println("\nTool calls requested:")
@@ -4,25 +4,76 @@ import "go.opentelemetry.io/otel/attribute"
 
 const (
 	// LLM
-	LLMVendor                = attribute.Key("llm.vendor")
-	LLMRequestType           = attribute.Key("llm.request.type")
-	LLMRequestModel          = attribute.Key("llm.request.model")
-	LLMResponseModel         = attribute.Key("llm.response.model")
-	LLMRequestMaxTokens      = attribute.Key("llm.request.max_tokens")
-	LLMUsageTotalTokens      = attribute.Key("llm.usage.total_tokens")
-	LLMUsageCompletionTokens = attribute.Key("llm.usage.completion_tokens")
-	LLMUsagePromptTokens     = attribute.Key("llm.usage.prompt_tokens")
-	LLMTemperature           = attribute.Key("llm.temperature")
-	LLMUser                  = attribute.Key("llm.user")
-	LLMHeaders               = attribute.Key("llm.headers")
-	LLMTopP                  = attribute.Key("llm.top_p")
-	LLMTopK                  = attribute.Key("llm.top_k")
-	LLMFrequencyPenalty      = attribute.Key("llm.frequency_penalty")
-	LLMPresencePenalty       = attribute.Key("llm.presence_penalty")
-	LLMPrompts               = attribute.Key("llm.prompts")
-	LLMCompletions           = attribute.Key("llm.completions")
-	LLMChatStopSequence      = attribute.Key("llm.chat.stop_sequences")
-	LLMRequestFunctions      = attribute.Key("llm.request.functions")
+	LLMVendor                        = attribute.Key("llm.vendor")
+	LLMRequestType                   = attribute.Key("llm.request.type")
+	LLMRequestModel                  = attribute.Key("llm.request.model")
+	LLMResponseModel                 = attribute.Key("llm.response.model")
+	LLMResponseID                    = attribute.Key("llm.response.id")
+	LLMRequestMaxTokens              = attribute.Key("llm.request.max_tokens")
+	LLMUsageTotalTokens              = attribute.Key("llm.usage.total_tokens")
+	LLMUsageCompletionTokens         = attribute.Key("llm.usage.completion_tokens")
+	LLMUsagePromptTokens             = attribute.Key("llm.usage.prompt_tokens")
+	LLMUsageCacheCreationInputTokens = attribute.Key("llm.usage.cache_creation_input_tokens")
+	LLMUsageCacheReadInputTokens     = attribute.Key("llm.usage.cache_read_input_tokens")
+	LLMUsageReasoningTokens          = attribute.Key("llm.usage.reasoning_tokens")
+	LLMUsagePromptAudioTokens        = attribute.Key("llm.usage.prompt_audio_tokens")
+	LLMUsageCompletionAudioTokens    = attribute.Key("llm.usage.completion_audio_tokens")
+	LLMUsagePromptImageTokens        = attribute.Key("llm.usage.prompt_image_tokens")
+	LLMUsageCompletionImageTokens    = attribute.Key("llm.usage.completion_image_tokens")
+	LLMUsageCost                     = attribute.Key("llm.usage.cost")
+	LLMUsageEstimated                = attribute.Key("llm.usage.estimated")
+	LLMTemperature                   = attribute.Key("llm.temperature")
+	LLMUser                          = attribute.Key("llm.user")
+	LLMHeaders                       = attribute.Key("llm.headers")
+	LLMTopP                          = attribute.Key("llm.top_p")
+	LLMTopK                          = attribute.Key("llm.top_k")
+	LLMFrequencyPenalty              = attribute.Key("llm.frequency_penalty")
+	LLMPresencePenalty               = attribute.Key("llm.presence_penalty")
+	LLMPrompts                       = attribute.Key("llm.prompts")
+	LLMCompletions                   = attribute.Key("llm.completions")
+	LLMChatStopSequence              = attribute.Key("llm.chat.stop_sequences")
+	LLMRequestFunctions              = attribute.Key("llm.request.functions")
+	LLMRequestSeed                   = attribute.Key("llm.request.seed")
+	LLMRequestN                      = attribute.Key("llm.request.n")
+	LLMRequestResponseFormat         = attribute.Key("llm.request.response_format")
+	LLMRequestToolChoice             = attribute.Key("llm.request.tool_choice")
+	LLMRequestLogitBias              = attribute.Key("llm.request.logit_bias")
+	LLMIsStreaming                   = attribute.Key("llm.is_streaming")
+	LLMResponseTimeToFirstToken      = attribute.Key("llm.response.time_to_first_token")
+	LLMResponseTokensPerSecond       = attribute.Key("llm.response.tokens_per_second")
+	LLMErrorType                     = attribute.Key("llm.error.type")
+	LLMRequestEmbeddingDimensions    = attribute.Key("llm.request.embedding.dimensions")
+	LLMRequestEmbeddingInputCount    = attribute.Key("llm.request.embedding.input_count")
+	LLMRequestEmbeddingFormat        = attribute.Key("llm.request.embedding.encoding_format")
+	LLMResponseEmbeddingDimensions   = attribute.Key("llm.response.embedding.dimensions")
+	LLMResponseEmbeddingCount        = attribute.Key("llm.response.embedding.count")
+	LLMPromptFeedbackBlockReason     = attribute.Key("llm.prompt_feedback.block_reason")
+	LLMPromptFeedbackSafetyRatings   = attribute.Key("llm.prompt_feedback.safety_ratings")
+	LLMOllamaTotalDuration           = attribute.Key("llm.ollama.total_duration")
+	LLMOllamaLoadDuration            = attribute.Key("llm.ollama.load_duration")
+	LLMOllamaPromptEvalDuration      = attribute.Key("llm.ollama.prompt_eval_duration")
+	LLMOllamaEvalDuration            = attribute.Key("llm.ollama.eval_duration")
+
+	// OpenTelemetry GenAI semantic conventions
+	GenAISystem                  = attribute.Key("gen_ai.system")
+	GenAIOperationName           = attribute.Key("gen_ai.operation.name")
+	GenAIRequestModel            = attribute.Key("gen_ai.request.model")
+	GenAIRequestMaxTokens        = attribute.Key("gen_ai.request.max_tokens")
+	GenAIRequestTemperature      = attribute.Key("gen_ai.request.temperature")
+	GenAIRequestTopP             = attribute.Key("gen_ai.request.top_p")
+	GenAIRequestTopK             = attribute.Key("gen_ai.request.top_k")
+	GenAIRequestFrequencyPenalty = attribute.Key("gen_ai.request.frequency_penalty")
+	GenAIRequestPresencePenalty  = attribute.Key("gen_ai.request.presence_penalty")
+	GenAIRequestStopSequences    = attribute.Key("gen_ai.request.stop_sequences")
+	GenAIRequestSeed             = attribute.Key("gen_ai.request.seed")
+	GenAIRequestChoiceCount      = attribute.Key("gen_ai.request.choice.count")
+	GenAIRequestEncodingFormats  = attribute.Key("gen_ai.request.encoding_formats")
+	GenAIResponseID              = attribute.Key("gen_ai.response.id")
+	GenAIResponseModel           = attribute.Key("gen_ai.response.model")
+	GenAIResponseFinishReasons   = attribute.Key("gen_ai.response.finish_reasons")
+	GenAIUsageInputTokens        = attribute.Key("gen_ai.usage.input_tokens")
+	GenAIUsageOutputTokens       = attribute.Key("gen_ai.usage.output_tokens")
+	ErrorType                    = attribute.Key("error.type")
 
 	// Vector DB
 	VectorDBVendor    = attribute.Key("vector_db.vendor")
@@ -32,5 +83,15 @@ const (
 	TraceloopSpanKind              = attribute.Key("traceloop.span.kind")
 	TraceloopWorkflowName          = attribute.Key("traceloop.workflow.name")
 	TraceloopEntityName            = attribute.Key("traceloop.entity.name")
+	TraceloopEntityInput           = attribute.Key("traceloop.entity.input")
+	TraceloopEntityOutput          = attribute.Key("traceloop.entity.output")
+	TraceloopEntityPath            = attribute.Key("traceloop.entity.path")
 	TraceloopAssociationProperties = attribute.Key("traceloop.association.properties")
+	TraceloopToolCallID            = attribute.Key("traceloop.tool.call_id")
+
+	// Agents
+	TraceloopAgentName              = attribute.Key("traceloop.agent.name")
+	TraceloopAgentIteration         = attribute.Key("traceloop.agent.iteration")
+	TraceloopAgentMaxIterations     = attribute.Key("traceloop.agent.max_iterations")
+	TraceloopAgentTerminationReason = attribute.Key("traceloop.agent.termination_reason")
 )
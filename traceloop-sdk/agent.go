@@ -0,0 +1,89 @@
+package traceloop
+
+import (
+	"context"
+
+	semconvai "github.com/traceloop/go-openllmetry/semconv-ai"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Termination reasons recorded when an agent ends.
+const (
+	AgentFinished      = "finished"
+	AgentMaxIterations = "max_iterations"
+	AgentError         = "error"
+	AgentCancelled     = "cancelled"
+)
+
+// Agent traces a loop in which a model repeatedly picks tools to call until
+// it can answer. Each turn of the loop is a task started with NewIteration,
+// under which the LLM calls and the tool executions of the turn are logged.
+type Agent struct {
+	workflow   *Workflow
+	ctx        context.Context
+	iteration  int
+	Attributes AgentAttributes `json:"agent_attributes"`
+}
+
+func (workflow *Workflow) NewAgent(attrs AgentAttributes) *Agent {
+	aCtx, span := workflow.sdk.startEntity(workflow.ctx, workflow.Attributes.Name, "agent", attrs.Name)
+
+	span.SetAttributes(semconvai.TraceloopAgentName.String(attrs.Name))
+	if attrs.MaxIterations > 0 {
+		span.SetAttributes(semconvai.TraceloopAgentMaxIterations.Int(attrs.MaxIterations))
+	}
+
+	return &Agent{
+		workflow:   workflow,
+		ctx:        aCtx,
+		Attributes: attrs,
+	}
+}
+
+// Context returns the context carrying the agent span.
+func (agent *Agent) Context() context.Context {
+	return agent.ctx
+}
+
+// NewIteration starts the task of the next turn of the agent loop, named
+// "iteration" under the agent. Iterations are numbered from 1 in the
+// traceloop.agent.iteration attribute.
+func (agent *Agent) NewIteration() *Task {
+	agent.iteration++
+
+	task := agent.workflow.newTask(agent.ctx, "iteration")
+	trace.SpanFromContext(task.ctx).SetAttributes(
+		semconvai.TraceloopAgentName.String(agent.Attributes.Name),
+		semconvai.TraceloopAgentIteration.Int(agent.iteration),
+	)
+
+	return task
+}
+
+// Iteration returns the number of the current iteration, 0 before the first
+// one is started.
+func (agent *Agent) Iteration() int {
+	return agent.iteration
+}
+
+// End records why the agent loop stopped, one of the Agent* reasons or a
+// reason of the caller's own, along with the number of iterations it ran,
+// and ends the agent span.
+func (agent *Agent) End(reason string) {
+	span := trace.SpanFromContext(agent.ctx)
+	span.SetAttributes(
+		semconvai.TraceloopAgentIteration.Int(agent.iteration),
+		semconvai.TraceloopAgentTerminationReason.String(reason),
+	)
+	span.End()
+}
+
+func (agent *Agent) LogPrompt(prompt Prompt) (LLMSpan, error) {
+	return agent.workflow.sdk.LogPrompt(agent.ctx, prompt, agent.workflow.Attributes)
+}
+
+// NewTool starts the span of a tool executed by the agent outside of an
+// iteration.
+func (agent *Agent) NewTool(toolCall ToolCall) *ToolSpan {
+	return agent.workflow.sdk.newTool(agent.ctx, agent.workflow.Attributes.Name, toolCall)
+}
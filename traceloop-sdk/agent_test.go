@@ -0,0 +1,75 @@
+package traceloop
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestAgent(t *testing.T) {
+	tl, exporter := newTestTraceloop(t)
+	ctx := context.Background()
+
+	wf := tl.NewWorkflow(ctx, WorkflowAttributes{Name: "support"})
+	agent := wf.NewAgent(AgentAttributes{Name: "researcher", MaxIterations: 5})
+	for i := 0; i < 2; i++ {
+		iteration := agent.NewIteration()
+		llmSpan, _ := iteration.LogPrompt(Prompt{Vendor: "openai", Mode: "chat"})
+		llmSpan.LogCompletion(ctx, Completion{}, Usage{})
+		iteration.End()
+	}
+	agent.End(AgentFinished)
+	wf.End()
+
+	spans := make(map[string][]tracetest.SpanStub)
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = append(spans[span.Name], span)
+	}
+
+	agentSpans := spans["researcher.agent"]
+	if len(agentSpans) != 1 {
+		t.Fatalf("Expected an agent span, got %v", spans)
+	}
+	if agentSpans[0].Parent.SpanID() != spans["support.workflow"][0].SpanContext.SpanID() {
+		t.Errorf("Expected the agent span to be a child of the workflow span")
+	}
+
+	iterations := spans["iteration.task"]
+	if len(iterations) != 2 {
+		t.Fatalf("Expected 2 iteration spans, got %d", len(iterations))
+	}
+	for i, iteration := range iterations {
+		if iteration.Parent.SpanID() != agentSpans[0].SpanContext.SpanID() {
+			t.Errorf("Expected iteration %d to be a child of the agent span", i+1)
+		}
+		attrs := spanAttributeMap(iteration)
+		if got := attrs["traceloop.agent.iteration"]; got != int64(i+1) {
+			t.Errorf("Expected iteration number %d, got %v", i+1, got)
+		}
+		if got := attrs["traceloop.entity.path"]; got != "researcher.iteration" {
+			t.Errorf("Expected the iteration to be nested under the agent's path, got %v", got)
+		}
+	}
+	for _, llmSpan := range spans["openai.chat"] {
+		if llmSpan.Parent.SpanID() != iterations[0].SpanContext.SpanID() && llmSpan.Parent.SpanID() != iterations[1].SpanContext.SpanID() {
+			t.Errorf("Expected the LLM spans to be children of the iterations")
+		}
+	}
+
+	expected := map[string]interface{}{
+		"traceloop.span.kind":                "agent",
+		"traceloop.workflow.name":            "support",
+		"traceloop.entity.name":              "researcher",
+		"traceloop.agent.name":               "researcher",
+		"traceloop.agent.max_iterations":     int64(5),
+		"traceloop.agent.iteration":          int64(2),
+		"traceloop.agent.termination_reason": "finished",
+	}
+	attrs := spanAttributeMap(agentSpans[0])
+	for key, value := range expected {
+		if attrs[key] != value {
+			t.Errorf("Attribute %s: expected %v, got %v", key, value, attrs[key])
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package traceloop
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	semconvai "github.com/traceloop/go-openllmetry/semconv-ai"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// associationPropertyPrefix prefixes the association properties, both as
+// span attributes and as baggage members.
+var associationPropertyPrefix = string(semconvai.TraceloopAssociationProperties) + "."
+
+// WithAssociationProperties returns a copy of ctx carrying the association
+// properties (e.g. user_id, session_id), added to those ctx already carries.
+// They are recorded on every workflow, task, agent, tool and LLM span started
+// with the returned context or one derived from it.
+//
+// The properties stay in the process: they are not added to the baggage of
+// ctx, which propagators would send to every host. InjectWorkflow, and the
+// transports built on it, propagate them to the services it is used with.
+func WithAssociationProperties(ctx context.Context, properties map[string]string) context.Context {
+	if len(properties) == 0 {
+		return ctx
+	}
+
+	merged := AssociationPropertiesFromContext(ctx)
+	for key, value := range properties {
+		merged[key] = value
+	}
+
+	return context.WithValue(ctx, associationPropertiesKey{}, merged)
+}
+
+type associationPropertiesKey struct{}
+
+// setBaggageMember returns bag with the member set, or bag unchanged when the
+// member cannot be set, e.g. because its key is not a valid token.
+func setBaggageMember(bag baggage.Baggage, key, value string) baggage.Baggage {
+	member, err := baggage.NewMemberRaw(key, value)
+	if err != nil {
+		fmt.Printf("Invalid baggage member %s: %v\n", key, err)
+		return bag
+	}
+
+	updated, err := bag.SetMember(member)
+	if err != nil {
+		fmt.Printf("Failed to set baggage member %s: %v\n", key, err)
+		return bag
+	}
+
+	return updated
+}
+
+// AssociationPropertiesFromContext returns a copy of the association
+// properties carried by ctx, including those extracted from an incoming
+// request by ExtractWorkflow.
+func AssociationPropertiesFromContext(ctx context.Context) map[string]string {
+	properties := make(map[string]string)
+	carried, _ := ctx.Value(associationPropertiesKey{}).(map[string]string)
+	for key, value := range carried {
+		properties[key] = value
+	}
+
+	return properties
+}
+
+// associationPropertiesBaggage returns bag with the association properties
+// carried by ctx added as members.
+func associationPropertiesBaggage(ctx context.Context, bag baggage.Baggage) baggage.Baggage {
+	for key, value := range AssociationPropertiesFromContext(ctx) {
+		bag = setBaggageMember(bag, associationPropertyPrefix+key, value)
+	}
+
+	return bag
+}
+
+// associationPropertiesFromBaggage returns the association properties
+// propagated as members of bag.
+func associationPropertiesFromBaggage(bag baggage.Baggage) map[string]string {
+	properties := make(map[string]string)
+	for _, member := range bag.Members() {
+		if key, ok := strings.CutPrefix(member.Key(), associationPropertyPrefix); ok {
+			properties[key] = member.Value()
+		}
+	}
+
+	return properties
+}
+
+// associationPropertiesAttributes returns the attributes of the association
+// properties carried by ctx, overridden by the given ones.
+func associationPropertiesAttributes(ctx context.Context, properties map[string]string) []attribute.KeyValue {
+	merged := AssociationPropertiesFromContext(ctx)
+	for key, value := range properties {
+		merged[key] = value
+	}
+
+	attrs := make([]attribute.KeyValue, 0, len(merged))
+	for key, value := range merged {
+		attrs = append(attrs, attribute.String(associationPropertyPrefix+key, value))
+	}
+
+	return attrs
+}
@@ -0,0 +1,78 @@
+package traceloop
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func TestAssociationProperties(t *testing.T) {
+	tl, exporter := newTestTraceloop(t)
+
+	ctx := WithAssociationProperties(context.Background(), map[string]string{
+		"user_id": "user-1",
+		"tenant":  "acme",
+	})
+	wf := tl.NewWorkflow(ctx, WorkflowAttributes{
+		Name:                  "support",
+		AssociationProperties: map[string]string{"session_id": "session-1"},
+	})
+	task := wf.NewTask("answer")
+	tool := task.NewTool(ToolCall{Function: ToolCallFunction{Name: "lookup"}})
+	tool.LogResult(ctx, "found")
+	llmSpan, _ := tl.LogPrompt(task.Context(), Prompt{Vendor: "openai", Mode: "chat"}, WorkflowAttributes{
+		Name:                  "support",
+		AssociationProperties: map[string]string{"tenant": "globex"},
+	})
+	llmSpan.LogCompletion(ctx, Completion{}, Usage{})
+	task.End()
+	wf.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 4 {
+		t.Fatalf("Expected 4 spans, got %d", len(spans))
+	}
+	for _, span := range spans {
+		expected := map[string]string{
+			"traceloop.association.properties.user_id":    "user-1",
+			"traceloop.association.properties.session_id": "session-1",
+			"traceloop.association.properties.tenant":     "acme",
+		}
+		if span.Name == "openai.chat" {
+			expected["traceloop.association.properties.tenant"] = "globex"
+		}
+
+		attrs := spanAttributeMap(span)
+		for key, value := range expected {
+			if attrs[key] != value {
+				t.Errorf("Span %s attribute %s: expected %v, got %v", span.Name, key, value, attrs[key])
+			}
+		}
+	}
+}
+
+func TestAssociationPropertiesPropagation(t *testing.T) {
+	tl, _ := newTestTraceloop(t)
+	ctx := WithAssociationProperties(context.Background(), map[string]string{
+		"user_id":    "user-1",
+		"session_id": "session with spaces",
+	})
+
+	header := http.Header{}
+	propagation.Baggage{}.Inject(ctx, propagation.HeaderCarrier(header))
+	if header.Get("baggage") != "" {
+		t.Fatalf("Expected the association properties not to be in the baggage of the context, got %s", header.Get("baggage"))
+	}
+
+	InjectWorkflow(ctx, propagation.HeaderCarrier(header))
+	if header.Get("baggage") == "" {
+		t.Fatal("Expected the association properties to be injected as baggage")
+	}
+
+	properties := AssociationPropertiesFromContext(tl.ExtractWorkflow(context.Background(), propagation.HeaderCarrier(header)))
+	if len(properties) != 2 || properties["user_id"] != "user-1" || properties["session_id"] != "session with spaces" {
+		t.Errorf("Expected the association properties to be extracted, got %v", properties)
+	}
+}
@@ -1,11 +1,41 @@
 package traceloop
 
-import "time"
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
 
 type BackoffConfig struct {
 	MaxRetries uint64
 }
 
+// PricingConfig configures the cost computed on LLM spans from the usage they
+// report.
+type PricingConfig struct {
+	// Prices overrides and extends the built-in prices of DefaultPrices, by
+	// model name.
+	Prices PriceTable
+	// Disabled turns off the cost computation.
+	Disabled bool
+}
+
+// TokenCountingConfig configures the estimation of the token usage of LLM
+// calls whose response does not report it, e.g. some streamed responses.
+type TokenCountingConfig struct {
+	// Enabled turns on the estimation. The tiktoken encoding of an OpenAI
+	// model is loaded in the background the first time a call to the model
+	// is estimated, and until then its usage is not estimated. Unless a loader
+	// is set with tiktoken.SetBpeLoader, loading downloads the encoding from
+	// the network, so set one in air-gapped deployments.
+	Enabled bool
+	// Tokenizers overrides and extends the built-in tokenizers of OpenAI
+	// models, by model name prefix.
+	Tokenizers map[string]Tokenizer
+}
+
 type Config struct {
 	BaseURL         string
 	APIKey          string
@@ -14,4 +44,30 @@ type Config struct {
 	ServiceName     string
 	PollingInterval time.Duration
 	BackoffConfig   BackoffConfig
+	// Exporter replaces the OTLP exporter sending spans to BaseURL, e.g. with
+	// an in-memory exporter in tests.
+	Exporter trace.SpanExporter
+	// DisableBatch exports each span as soon as it ends rather than in
+	// batches, so short-lived programs and tests see every span.
+	DisableBatch  bool
+	BinaryContent BinaryContentConfig
+	// DisableEmbeddingInputs stops recording the texts passed to embedding
+	// models, which can be large when indexing documents. Their count is
+	// still recorded.
+	DisableEmbeddingInputs bool
+	Pricing                PricingConfig
+	TokenCounting          TokenCountingConfig
+	// Semconv selects the semantic conventions of the attributes recorded on
+	// LLM spans: the legacy llm.* ones, the OpenTelemetry gen_ai.* ones, or
+	// both. It defaults to the legacy conventions.
+	Semconv SemconvMode
+	// Messages selects how the prompts and the completions are recorded: as
+	// span attributes, or as events emitted through LoggerProvider.
+	Messages MessagesMode
+	// LoggerProvider emits the events of the messages when Messages is
+	// MessagesEvents. The global logger provider is used when it is not set.
+	LoggerProvider log.LoggerProvider
+	// MeterProvider records the SDK's metrics, such as the cost of the LLM
+	// calls. The global meter provider is used when it is not set.
+	MeterProvider metric.MeterProvider
 }
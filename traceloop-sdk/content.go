@@ -0,0 +1,163 @@
+package traceloop
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+// Content part types. Text parts carry Text; image, audio and file parts
+// carry either a URL, inline Data with its MimeType, or a FileID referencing
+// a file uploaded to the vendor.
+const (
+	ContentPartText  = "text"
+	ContentPartImage = "image"
+	ContentPartAudio = "audio"
+	ContentPartFile  = "file"
+)
+
+// BinaryContentMode is how the inline data of image, audio and file parts
+// larger than BinaryContentConfig.MaxInlineSize is recorded.
+type BinaryContentMode string
+
+const (
+	// BinaryContentHash records the SHA-256 of the data. It is the default.
+	BinaryContentHash BinaryContentMode = "hash"
+	// BinaryContentDrop records only the size and the MIME type.
+	BinaryContentDrop BinaryContentMode = "drop"
+	// BinaryContentUpload passes the data to BinaryContentConfig.Upload and
+	// records the reference it returns as the part's URL.
+	BinaryContentUpload BinaryContentMode = "upload"
+)
+
+// BinaryUploader stores the data of a content part, e.g. in object storage,
+// and returns a URL referencing it.
+type BinaryUploader func(ctx context.Context, part ContentPart) (string, error)
+
+// BinaryContentConfig keeps spans within the size limits of exporters and
+// backends when messages carry images, audio or files.
+type BinaryContentConfig struct {
+	Mode BinaryContentMode
+	// MaxInlineSize is the size in bytes up to which data is recorded
+	// base64-encoded on the span. It defaults to 0, never recording data.
+	MaxInlineSize int
+	// Upload is called synchronously, while the prompt or the completion is
+	// logged. If it fails, the data is hashed instead.
+	Upload BinaryUploader
+}
+
+func setContentPartsAttribute(ctx context.Context, span apitrace.Span, messagePrefix string, parts []ContentPart, config BinaryContentConfig) {
+	for i, part := range parts {
+		partPrefix := fmt.Sprintf("%s.content_parts.%d", messagePrefix, i)
+		var attrs []attribute.KeyValue
+		for _, attr := range contentPartAttributes(ctx, part, config) {
+			attrs = append(attrs, attribute.KeyValue{Key: attribute.Key(partPrefix + "." + string(attr.Key)), Value: attr.Value})
+		}
+
+		span.SetAttributes(attrs...)
+	}
+}
+
+// contentPartAttributes returns the fields of a content part, keyed by their
+// name within the part.
+func contentPartAttributes(ctx context.Context, part ContentPart, config BinaryContentConfig) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{attribute.String("type", part.Type)}
+
+	if part.Text != "" {
+		attrs = append(attrs, attribute.String("text", part.Text))
+	}
+	if part.FileID != "" {
+		attrs = append(attrs, attribute.String("file_id", part.FileID))
+	}
+	if part.Filename != "" {
+		attrs = append(attrs, attribute.String("filename", part.Filename))
+	}
+
+	// Data URLs embed the payload, so they are handled as inline data.
+	if data, mimeType, ok := parseDataURL(part.URL); ok {
+		part.URL = ""
+		part.Data = data
+		if part.MimeType == "" {
+			part.MimeType = mimeType
+		}
+	}
+	if part.URL != "" {
+		attrs = append(attrs, attribute.String("url", part.URL))
+	}
+	if part.MimeType != "" {
+		attrs = append(attrs, attribute.String("mime_type", part.MimeType))
+	}
+	if len(part.Data) > 0 {
+		attrs = append(attrs, binaryContentAttributes(ctx, part, config)...)
+	}
+
+	return attrs
+}
+
+func binaryContentAttributes(ctx context.Context, part ContentPart, config BinaryContentConfig) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{attribute.Int("size", len(part.Data))}
+	if len(part.Data) <= config.MaxInlineSize {
+		return append(attrs, attribute.String("data", base64.StdEncoding.EncodeToString(part.Data)))
+	}
+
+	switch config.Mode {
+	case BinaryContentDrop:
+		return attrs
+	case BinaryContentUpload:
+		if config.Upload != nil {
+			url, err := config.Upload(ctx, part)
+			if err == nil {
+				return append(attrs, attribute.String("url", url))
+			}
+			fmt.Printf("Failed to upload %s content: %v\n", part.Type, err)
+		}
+	}
+
+	sum := sha256.Sum256(part.Data)
+	return append(attrs, attribute.String("sha256", hex.EncodeToString(sum[:])))
+}
+
+// parseDataURL decodes a base64 data URL such as
+// "data:image/png;base64,iVBORw0...".
+func parseDataURL(url string) ([]byte, string, bool) {
+	rest, ok := strings.CutPrefix(url, "data:")
+	if !ok {
+		return nil, "", false
+	}
+
+	header, payload, ok := strings.Cut(rest, ",")
+	if !ok {
+		return nil, "", false
+	}
+
+	mimeType, ok := strings.CutSuffix(header, ";base64")
+	if !ok {
+		return []byte(payload), mimeType, true
+	}
+
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, "", false
+	}
+
+	return data, mimeType, true
+}
+
+// partsText joins the text parts of a message, recorded as its content when
+// it has no plain content.
+func partsText(parts []ContentPart) string {
+	var texts []string
+	for _, part := range parts {
+		if part.Type == ContentPartText && part.Text != "" {
+			texts = append(texts, part.Text)
+		}
+	}
+
+	return strings.Join(texts, "\n")
+}
@@ -0,0 +1,99 @@
+package traceloop
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func logMultimodalPrompt(t *testing.T, config BinaryContentConfig) map[string]interface{} {
+	t.Helper()
+
+	tl, exporter := newTestTraceloop(t)
+	tl.config.BinaryContent = config
+	ctx := context.Background()
+
+	image := base64.StdEncoding.EncodeToString([]byte("not really a png"))
+	llmSpan, err := tl.LogPrompt(ctx, Prompt{
+		Vendor: "openai",
+		Mode:   "chat",
+		Model:  "gpt-4o",
+		Messages: []Message{{
+			Index: 0,
+			Role:  "user",
+			ContentParts: []ContentPart{
+				{Type: ContentPartText, Text: "What is in these images?"},
+				{Type: ContentPartImage, URL: "https://example.com/cat.jpg"},
+				{Type: ContentPartImage, URL: "data:image/png;base64," + image},
+				{Type: ContentPartFile, FileID: "file-1", Filename: "report.pdf"},
+			},
+		}},
+	}, WorkflowAttributes{})
+	if err != nil {
+		t.Fatalf("LogPrompt failed: %v", err)
+	}
+	llmSpan.LogCompletion(ctx, Completion{}, Usage{})
+
+	_, attrs := exportedSpan(t, exporter)
+	return attrs
+}
+
+func TestContentParts(t *testing.T) {
+	attrs := logMultimodalPrompt(t, BinaryContentConfig{})
+
+	expected := map[string]interface{}{
+		"llm.prompts.0.content":                   "What is in these images?",
+		"llm.prompts.0.content_parts.0.type":      "text",
+		"llm.prompts.0.content_parts.1.type":      "image",
+		"llm.prompts.0.content_parts.1.url":       "https://example.com/cat.jpg",
+		"llm.prompts.0.content_parts.2.mime_type": "image/png",
+		"llm.prompts.0.content_parts.2.size":      int64(16),
+		"llm.prompts.0.content_parts.2.sha256":    "e90137d39de304eefbbe788bc535c7e82f27abbf8069505fbbd8a9dcdc4f2024",
+		"llm.prompts.0.content_parts.3.file_id":   "file-1",
+		"llm.prompts.0.content_parts.3.filename":  "report.pdf",
+	}
+	for key, value := range expected {
+		if attrs[key] != value {
+			t.Errorf("Attribute %s: expected %v, got %v", key, value, attrs[key])
+		}
+	}
+	if _, ok := attrs["llm.prompts.0.content_parts.2.data"]; ok {
+		t.Errorf("Expected the image data not to be recorded")
+	}
+}
+
+func TestContentPartsBinaryModes(t *testing.T) {
+	attrs := logMultimodalPrompt(t, BinaryContentConfig{MaxInlineSize: 1024})
+	if attrs["llm.prompts.0.content_parts.2.data"] != base64.StdEncoding.EncodeToString([]byte("not really a png")) {
+		t.Errorf("Expected small data to be inlined, got %v", attrs["llm.prompts.0.content_parts.2.data"])
+	}
+
+	attrs = logMultimodalPrompt(t, BinaryContentConfig{Mode: BinaryContentDrop})
+	if _, ok := attrs["llm.prompts.0.content_parts.2.sha256"]; ok {
+		t.Errorf("Expected dropped data not to be hashed")
+	}
+	if attrs["llm.prompts.0.content_parts.2.size"] != int64(16) {
+		t.Errorf("Expected the size of dropped data, got %v", attrs["llm.prompts.0.content_parts.2.size"])
+	}
+
+	attrs = logMultimodalPrompt(t, BinaryContentConfig{
+		Mode: BinaryContentUpload,
+		Upload: func(ctx context.Context, part ContentPart) (string, error) {
+			return "s3://bucket/" + part.MimeType, nil
+		},
+	})
+	if attrs["llm.prompts.0.content_parts.2.url"] != "s3://bucket/image/png" {
+		t.Errorf("Expected the upload reference, got %v", attrs["llm.prompts.0.content_parts.2.url"])
+	}
+
+	attrs = logMultimodalPrompt(t, BinaryContentConfig{
+		Mode: BinaryContentUpload,
+		Upload: func(ctx context.Context, part ContentPart) (string, error) {
+			return "", errors.New("bucket not found")
+		},
+	})
+	if _, ok := attrs["llm.prompts.0.content_parts.2.sha256"]; !ok {
+		t.Errorf("Expected data to be hashed when the upload fails")
+	}
+}
@@ -0,0 +1,70 @@
+package traceloop
+
+import (
+	"context"
+
+	semconvai "github.com/traceloop/go-openllmetry/semconv-ai"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// EmbeddingMode is the request type of the spans logged by LogEmbeddingPrompt.
+const EmbeddingMode = "embedding"
+
+// LogEmbeddingPrompt starts an LLM span for a call to an embedding model. The
+// input texts are recorded as user prompts unless Config.DisableEmbeddingInputs
+// is set; their count is always recorded. Log the result with LogEmbeddings,
+// or the failure with LogError.
+func (instance *Traceloop) LogEmbeddingPrompt(ctx context.Context, prompt EmbeddingPrompt, workflowAttrs WorkflowAttributes) (LLMSpan, error) {
+	llmSpan := instance.startLLMSpan(ctx, prompt.Vendor, EmbeddingMode, prompt.Model, workflowAttrs)
+
+	inputCount := prompt.InputCount
+	if inputCount == 0 {
+		inputCount = len(prompt.Inputs)
+	}
+
+	attrs := []attribute.KeyValue{semconvai.LLMRequestEmbeddingInputCount.Int(inputCount)}
+	if prompt.Dimensions != 0 {
+		attrs = append(attrs, semconvai.LLMRequestEmbeddingDimensions.Int(prompt.Dimensions))
+	}
+	if prompt.EncodingFormat != "" {
+		attrs = append(attrs, semconvai.LLMRequestEmbeddingFormat.String(prompt.EncodingFormat))
+	}
+	if prompt.User != "" {
+		attrs = append(attrs, semconvai.LLMUser.String(prompt.User))
+	}
+	llmSpan.span.SetAttributes(attrs...)
+
+	if !instance.config.DisableEmbeddingInputs {
+		messages := make([]Message, len(prompt.Inputs))
+		for i, input := range prompt.Inputs {
+			messages[i] = Message{Index: i, Role: "user", Content: input}
+		}
+		llmSpan.logPromptMessages(ctx, messages)
+	}
+
+	return llmSpan, nil
+}
+
+// LogEmbeddings records the embeddings returned for the prompt and ends the
+// LLM span. Embedding models only report prompt tokens, which are also the
+// total when usage.TotalTokens is not set.
+func (llmSpan *LLMSpan) LogEmbeddings(ctx context.Context, embeddings Embeddings, usage Usage) error {
+	if usage.TotalTokens == 0 {
+		usage.TotalTokens = usage.PromptTokens
+	}
+
+	attrs := []attribute.KeyValue{
+		semconvai.LLMResponseModel.String(embeddings.Model),
+		semconvai.LLMUsagePromptTokens.Int(usage.PromptTokens),
+		semconvai.LLMUsageTotalTokens.Int(usage.TotalTokens),
+		semconvai.LLMResponseEmbeddingCount.Int(embeddings.Count),
+	}
+	if embeddings.Dimensions != 0 {
+		attrs = append(attrs, semconvai.LLMResponseEmbeddingDimensions.Int(embeddings.Dimensions))
+	}
+	llmSpan.span.SetAttributes(attrs...)
+	llmSpan.recordCost(ctx, embeddings.Model, usage)
+
+	llmSpan.span.End()
+	return nil
+}
@@ -0,0 +1,72 @@
+package traceloop
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLogEmbeddings(t *testing.T) {
+	tl, exporter := newTestTraceloop(t)
+	ctx := context.Background()
+
+	llmSpan, err := tl.LogEmbeddingPrompt(ctx, EmbeddingPrompt{
+		Vendor:     "openai",
+		Model:      "text-embedding-3-small",
+		Inputs:     []string{"first chunk", "second chunk"},
+		Dimensions: 256,
+	}, WorkflowAttributes{Name: "indexing"})
+	if err != nil {
+		t.Fatalf("LogEmbeddingPrompt failed: %v", err)
+	}
+	llmSpan.LogEmbeddings(ctx, Embeddings{
+		Model:      "text-embedding-3-small",
+		Count:      2,
+		Dimensions: 256,
+	}, Usage{PromptTokens: 6})
+
+	span, attrs := exportedSpan(t, exporter)
+	if span.Name != "openai.embedding" {
+		t.Errorf("Expected span name openai.embedding, got %s", span.Name)
+	}
+
+	expected := map[string]interface{}{
+		"llm.request.type":                  "embedding",
+		"llm.request.model":                 "text-embedding-3-small",
+		"traceloop.workflow.name":           "indexing",
+		"llm.request.embedding.input_count": int64(2),
+		"llm.request.embedding.dimensions":  int64(256),
+		"llm.prompts.0.content":             "first chunk",
+		"llm.prompts.1.role":                "user",
+		"llm.response.model":                "text-embedding-3-small",
+		"llm.response.embedding.count":      int64(2),
+		"llm.response.embedding.dimensions": int64(256),
+		"llm.usage.prompt_tokens":           int64(6),
+		"llm.usage.total_tokens":            int64(6),
+	}
+	for key, value := range expected {
+		if attrs[key] != value {
+			t.Errorf("Attribute %s: expected %v, got %v", key, value, attrs[key])
+		}
+	}
+}
+
+func TestLogEmbeddingsWithoutInputs(t *testing.T) {
+	tl, exporter := newTestTraceloop(t)
+	tl.config.DisableEmbeddingInputs = true
+	ctx := context.Background()
+
+	llmSpan, _ := tl.LogEmbeddingPrompt(ctx, EmbeddingPrompt{
+		Vendor: "openai",
+		Model:  "text-embedding-3-small",
+		Inputs: []string{"a confidential document"},
+	}, WorkflowAttributes{})
+	llmSpan.LogEmbeddings(ctx, Embeddings{Count: 1}, Usage{})
+
+	_, attrs := exportedSpan(t, exporter)
+	if _, exists := attrs["llm.prompts.0.content"]; exists {
+		t.Error("Expected the input texts not to be recorded")
+	}
+	if attrs["llm.request.embedding.input_count"] != int64(1) {
+		t.Errorf("Expected the input count to be recorded, got %v", attrs["llm.request.embedding.input_count"])
+	}
+}
@@ -0,0 +1,174 @@
+package traceloop
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	semconvai "github.com/traceloop/go-openllmetry/semconv-ai"
+	"go.opentelemetry.io/otel/codes"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+// Error types recorded in the llm.error.type attribute of failed LLM calls.
+const (
+	ErrorTypeRateLimit      = "rate_limit"
+	ErrorTypeContextLength  = "context_length_exceeded"
+	ErrorTypeContentFilter  = "content_filter"
+	ErrorTypeTimeout        = "timeout"
+	ErrorTypeAuthentication = "auth"
+	ErrorTypeOther          = "other"
+)
+
+// errorCodes map the error codes of typed provider errors, such as the AWS
+// and gRPC error codes, to error types. They are lowercased.
+var errorCodes = map[string]string{
+	"context_length_exceeded":       ErrorTypeContextLength,
+	"content_filter":                ErrorTypeContentFilter,
+	"rate_limit_exceeded":           ErrorTypeRateLimit,
+	"insufficient_quota":            ErrorTypeRateLimit,
+	"throttlingexception":           ErrorTypeRateLimit,
+	"servicequotaexceededexception": ErrorTypeRateLimit,
+	"resource_exhausted":            ErrorTypeRateLimit,
+	"accessdeniedexception":         ErrorTypeAuthentication,
+	"unrecognizedclientexception":   ErrorTypeAuthentication,
+	"expiredtokenexception":         ErrorTypeAuthentication,
+	"unauthenticated":               ErrorTypeAuthentication,
+	"permission_denied":             ErrorTypeAuthentication,
+	"modeltimeoutexception":         ErrorTypeTimeout,
+	"deadline_exceeded":             ErrorTypeTimeout,
+}
+
+// errorPatterns match the messages of untyped provider errors. They are
+// checked in order, against the lowercased message, so they only list phrases
+// specific to one error type.
+var errorPatterns = []struct {
+	errorType string
+	patterns  []string
+}{
+	{ErrorTypeContextLength, []string{
+		"context_length_exceeded", "maximum context length", "context window", "prompt is too long",
+		"input is too long",
+	}},
+	{ErrorTypeContentFilter, []string{
+		"content_filter", "content filter", "content management policy", "content_policy",
+	}},
+	{ErrorTypeRateLimit, []string{
+		"rate limit", "rate_limit", "too many requests", "throttl", "resource_exhausted",
+		"insufficient_quota", "exceeded your current quota",
+	}},
+	{ErrorTypeAuthentication, []string{
+		"unauthorized", "unauthenticated", "invalid api key", "invalid_api_key", "incorrect api key",
+		"authentication_error",
+	}},
+	{ErrorTypeTimeout, []string{"timeout", "timed out", "deadline exceeded"}},
+}
+
+type ErrorOption func(*errorOptions)
+
+type errorOptions struct {
+	statusCode int
+	errorType  string
+}
+
+// WithStatusCode sets the HTTP status code of the failed call, for provider
+// errors that carry it in a field rather than a StatusCode method.
+func WithStatusCode(statusCode int) ErrorOption {
+	return func(o *errorOptions) {
+		o.statusCode = statusCode
+	}
+}
+
+// WithErrorType sets the error type, skipping the classification of the
+// error.
+func WithErrorType(errorType string) ErrorOption {
+	return func(o *errorOptions) {
+		o.errorType = errorType
+	}
+}
+
+// LogError records a failed LLM call and ends the span. The error is
+// recorded as an exception event and classified into one of the ErrorType
+// constants.
+func (llmSpan *LLMSpan) LogError(ctx context.Context, err error, opts ...ErrorOption) {
+	recordError(llmSpan.span, err, opts...)
+	llmSpan.span.End()
+}
+
+func recordError(span apitrace.Span, err error, opts ...ErrorOption) {
+	var options errorOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	errorType := options.errorType
+	if errorType == "" {
+		errorType = ClassifyError(err, options.statusCode)
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	span.SetAttributes(semconvai.LLMErrorType.String(errorType))
+}
+
+// ClassifyError returns the type of a provider error. The status code is
+// optional: when it is 0, it is read from the error if it has a StatusCode
+// or HTTPStatusCode method. The error is classified by its type, its status
+// code and, for errors with an ErrorCode method such as the AWS errors, its
+// error code. Only errors none of them classify are matched by message.
+func ClassifyError(err error, statusCode int) string {
+	if err == nil {
+		return ""
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ErrorTypeTimeout
+	}
+
+	if statusCode == 0 {
+		statusCode = errorStatusCode(err)
+	}
+	switch statusCode {
+	case http.StatusTooManyRequests:
+		return ErrorTypeRateLimit
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrorTypeAuthentication
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return ErrorTypeTimeout
+	}
+
+	var codeErr interface{ ErrorCode() string }
+	if errors.As(err, &codeErr) {
+		if errorType, ok := errorCodes[strings.ToLower(codeErr.ErrorCode())]; ok {
+			return errorType
+		}
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, errorPattern := range errorPatterns {
+		for _, pattern := range errorPattern.patterns {
+			if strings.Contains(message, pattern) {
+				return errorPattern.errorType
+			}
+		}
+	}
+
+	return ErrorTypeOther
+}
+
+func errorStatusCode(err error) int {
+	var statusErr interface{ StatusCode() int }
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode()
+	}
+
+	var httpStatusErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &httpStatusErr) {
+		return httpStatusErr.HTTPStatusCode()
+	}
+
+	return 0
+}
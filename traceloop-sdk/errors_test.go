@@ -0,0 +1,86 @@
+package traceloop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+)
+
+type statusCodeError struct {
+	statusCode int
+}
+
+func (e *statusCodeError) Error() string {
+	return http.StatusText(e.statusCode)
+}
+
+func (e *statusCodeError) HTTPStatusCode() int {
+	return e.statusCode
+}
+
+type errorCodeError struct {
+	code string
+}
+
+func (e *errorCodeError) Error() string {
+	return "request failed"
+}
+
+func (e *errorCodeError) ErrorCode() string {
+	return e.code
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		statusCode int
+		expected   string
+	}{
+		{"status code", errors.New("request failed"), http.StatusTooManyRequests, ErrorTypeRateLimit},
+		{"status code method", fmt.Errorf("call: %w", &statusCodeError{http.StatusUnauthorized}), 0, ErrorTypeAuthentication},
+		{"deadline", fmt.Errorf("call: %w", context.DeadlineExceeded), 0, ErrorTypeTimeout},
+		{"error code", &errorCodeError{"ThrottlingException"}, http.StatusBadRequest, ErrorTypeRateLimit},
+		{"unknown error code", &errorCodeError{"ValidationException"}, http.StatusBadRequest, ErrorTypeOther},
+		{"status code before message", errors.New("prompt is too long"), http.StatusTooManyRequests, ErrorTypeRateLimit},
+		{"context length", errors.New("This model's maximum context length is 128000 tokens"), http.StatusBadRequest, ErrorTypeContextLength},
+		{"content filter", errors.New("The response was filtered due to the prompt triggering content management policy"), 0, ErrorTypeContentFilter},
+		{"safety in message", errors.New("model safety-v2 is not available in this region"), http.StatusBadRequest, ErrorTypeOther},
+		{"permission in message", errors.New("the file has no read permission"), 0, ErrorTypeOther},
+		{"other", errors.New("internal server error"), http.StatusInternalServerError, ErrorTypeOther},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if errorType := ClassifyError(test.err, test.statusCode); errorType != test.expected {
+				t.Errorf("Expected %s, got %s", test.expected, errorType)
+			}
+		})
+	}
+}
+
+func TestLogError(t *testing.T) {
+	tl, exporter := newTestTraceloop(t)
+	ctx := context.Background()
+
+	llmSpan, err := tl.LogPrompt(ctx, Prompt{Vendor: "openai", Mode: "chat", Model: "gpt-4o-mini"}, WorkflowAttributes{})
+	if err != nil {
+		t.Fatalf("LogPrompt failed: %v", err)
+	}
+	llmSpan.LogError(ctx, errors.New("Rate limit reached for gpt-4o-mini"))
+
+	span, attrs := exportedSpan(t, exporter)
+	if span.Status.Code != codes.Error || span.Status.Description != "Rate limit reached for gpt-4o-mini" {
+		t.Errorf("Expected the span status to be an error, got %v", span.Status)
+	}
+	if len(span.Events) != 1 || span.Events[0].Name != "exception" {
+		t.Errorf("Expected an exception event, got %v", span.Events)
+	}
+	if attrs["llm.error.type"] != ErrorTypeRateLimit {
+		t.Errorf("Expected the error type %s, got %v", ErrorTypeRateLimit, attrs["llm.error.type"])
+	}
+}
@@ -0,0 +1,145 @@
+package traceloop
+
+import (
+	"context"
+	"time"
+
+	semconvai "github.com/traceloop/go-openllmetry/semconv-ai"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+// MessagesMode is how the prompts and the completions of LLM spans are
+// recorded.
+type MessagesMode string
+
+const (
+	// MessagesAttributes records every message as indexed span attributes,
+	// e.g. llm.prompts.0.content. It is the default.
+	MessagesAttributes MessagesMode = "attributes"
+	// MessagesEvents emits every message as an OpenTelemetry GenAI event, a
+	// log record correlated to the LLM span, keeping the span small.
+	MessagesEvents MessagesMode = "events"
+)
+
+// GenAI event names.
+const (
+	SystemMessageEvent    = "gen_ai.system.message"
+	UserMessageEvent      = "gen_ai.user.message"
+	AssistantMessageEvent = "gen_ai.assistant.message"
+	ToolMessageEvent      = "gen_ai.tool.message"
+	ChoiceEvent           = "gen_ai.choice"
+)
+
+// messageEvents are the events of the message roles, and the roles they are
+// named after.
+var messageEvents = map[string]struct{ name, role string }{
+	"system":    {SystemMessageEvent, "system"},
+	"developer": {SystemMessageEvent, "system"},
+	"user":      {UserMessageEvent, "user"},
+	"assistant": {AssistantMessageEvent, "assistant"},
+	"model":     {AssistantMessageEvent, "assistant"},
+	"tool":      {ToolMessageEvent, "tool"},
+	"function":  {ToolMessageEvent, "tool"},
+}
+
+// logPromptMessages records the messages of a prompt.
+func (llmSpan *LLMSpan) logPromptMessages(ctx context.Context, messages []Message) {
+	if llmSpan.logger == nil {
+		setMessagesAttribute(ctx, llmSpan.span, "llm.prompts", messages, llmSpan.binaryContent)
+		return
+	}
+
+	for _, message := range messages {
+		event, ok := messageEvents[message.Role]
+		if !ok {
+			event = messageEvents["user"]
+		}
+
+		body := llmSpan.messageBody(ctx, message)
+		if message.Role != event.role {
+			body = append(body, log.String("role", message.Role))
+		}
+		if message.ToolCallID != "" {
+			body = append(body, log.String("id", message.ToolCallID))
+		}
+		llmSpan.emitEvent(ctx, event.name, body)
+	}
+}
+
+// logCompletionMessages records the messages of a completion, one per
+// choice.
+func (llmSpan *LLMSpan) logCompletionMessages(ctx context.Context, messages []Message) {
+	if llmSpan.logger == nil {
+		setMessagesAttribute(ctx, llmSpan.span, "llm.completions", messages, llmSpan.binaryContent)
+		return
+	}
+
+	for _, message := range messages {
+		body := []log.KeyValue{
+			log.Int("index", message.Index),
+			log.Map("message", append(llmSpan.messageBody(ctx, message), log.String("role", message.Role))...),
+		}
+		if message.FinishReason != "" {
+			body = append(body, log.String("finish_reason", message.FinishReason))
+		}
+		llmSpan.emitEvent(ctx, ChoiceEvent, body)
+	}
+}
+
+// messageBody returns the content and the tool calls of a message. Content
+// parts are recorded as on spans, their binary data included.
+func (llmSpan *LLMSpan) messageBody(ctx context.Context, message Message) []log.KeyValue {
+	content := message.Content
+	if content == "" {
+		content = partsText(message.ContentParts)
+	}
+	body := []log.KeyValue{log.String("content", content)}
+
+	if len(message.ContentParts) > 0 {
+		parts := make([]log.Value, len(message.ContentParts))
+		for i, part := range message.ContentParts {
+			parts[i] = logMap(contentPartAttributes(ctx, part, llmSpan.binaryContent))
+		}
+		body = append(body, log.Slice("content_parts", parts...))
+	}
+
+	if len(message.ToolCalls) > 0 {
+		toolCalls := make([]log.Value, len(message.ToolCalls))
+		for i, toolCall := range message.ToolCalls {
+			toolCalls[i] = log.MapValue(
+				log.String("id", toolCall.ID),
+				log.String("type", toolCall.Type),
+				log.Map("function",
+					log.String("name", toolCall.Function.Name),
+					log.String("arguments", toolCall.Function.Arguments),
+				),
+			)
+		}
+		body = append(body, log.Slice("tool_calls", toolCalls...))
+	}
+
+	return body
+}
+
+// emitEvent emits a GenAI event in the context of the LLM span, which
+// correlates it to the span.
+func (llmSpan *LLMSpan) emitEvent(ctx context.Context, name string, body []log.KeyValue) {
+	var record log.Record
+	record.SetEventName(name)
+	record.SetTimestamp(time.Now())
+	record.SetBody(log.MapValue(body...))
+	record.AddAttributes(log.String(string(semconvai.GenAISystem), llmSpan.system))
+
+	llmSpan.logger.Emit(apitrace.ContextWithSpan(ctx, llmSpan.span), record)
+}
+
+func logMap(attrs []attribute.KeyValue) log.Value {
+	kvs := make([]log.KeyValue, len(attrs))
+	for i, attr := range attrs {
+		kvs[i] = log.KeyValueFromAttribute(attr)
+	}
+
+	return log.MapValue(kvs...)
+}
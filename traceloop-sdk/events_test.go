@@ -0,0 +1,129 @@
+package traceloop
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/embedded"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+type emittedRecord struct {
+	spanContext apitrace.SpanContext
+	record      log.Record
+}
+
+// recordingLogger records the log records emitted through it.
+type recordingLogger struct {
+	embedded.Logger
+
+	mu      sync.Mutex
+	records []emittedRecord
+}
+
+func (l *recordingLogger) Emit(ctx context.Context, record log.Record) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.records = append(l.records, emittedRecord{apitrace.SpanContextFromContext(ctx), record})
+}
+
+func (l *recordingLogger) Enabled(context.Context, log.EnabledParameters) bool {
+	return true
+}
+
+func bodyMap(value log.Value) map[string]log.Value {
+	fields := make(map[string]log.Value)
+	for _, kv := range value.AsMap() {
+		fields[kv.Key] = kv.Value
+	}
+	return fields
+}
+
+func TestMessagesEvents(t *testing.T) {
+	tl, exporter := newTestTraceloop(t)
+	logger := &recordingLogger{}
+	tl.config.Messages = MessagesEvents
+	tl.logger = logger
+
+	ctx := context.Background()
+	llmSpan, _ := tl.LogPrompt(ctx, Prompt{
+		Vendor: "gemini",
+		Mode:   "chat",
+		Model:  "gemini-2.5-flash",
+		Messages: []Message{
+			{Index: 0, Role: "system", Content: "You are a weather bot."},
+			{Index: 1, Role: "user", ContentParts: []ContentPart{
+				{Type: ContentPartText, Text: "Weather here?"},
+				{Type: ContentPartImage, URL: "https://example.com/sky.jpg"},
+			}},
+			{Index: 2, Role: "tool", Content: "Sunny", ToolCallID: "call_1"},
+		},
+	}, WorkflowAttributes{})
+	llmSpan.LogCompletion(ctx, Completion{
+		Model: "gemini-2.5-flash",
+		Messages: []Message{{
+			Index:        0,
+			Role:         "model",
+			FinishReason: "tool_calls",
+			ToolCalls: []ToolCall{{
+				ID:       "call_2",
+				Type:     "function",
+				Function: ToolCallFunction{Name: "get_forecast", Arguments: `{"days":3}`},
+			}},
+		}},
+	}, Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15})
+
+	span, attrs := exportedSpan(t, exporter)
+	for key := range attrs {
+		if strings.HasPrefix(key, "llm.prompts.") || strings.HasPrefix(key, "llm.completions.") {
+			t.Errorf("Expected no message attribute, got %s", key)
+		}
+	}
+
+	expectedEvents := []string{SystemMessageEvent, UserMessageEvent, ToolMessageEvent, ChoiceEvent}
+	if len(logger.records) != len(expectedEvents) {
+		t.Fatalf("Expected %d events, got %d", len(expectedEvents), len(logger.records))
+	}
+	for i, emitted := range logger.records {
+		if emitted.record.EventName() != expectedEvents[i] {
+			t.Errorf("Event %d: expected %s, got %s", i, expectedEvents[i], emitted.record.EventName())
+		}
+		if emitted.spanContext.SpanID() != span.SpanContext.SpanID() {
+			t.Errorf("Event %d: expected to be correlated to the LLM span", i)
+		}
+		emitted.record.WalkAttributes(func(kv log.KeyValue) bool {
+			if kv.Key == "gen_ai.system" && kv.Value.AsString() != "gcp.gemini" {
+				t.Errorf("Event %d: expected gen_ai.system gcp.gemini, got %s", i, kv.Value.AsString())
+			}
+			return true
+		})
+	}
+
+	user := bodyMap(logger.records[1].record.Body())
+	if user["content"].AsString() != "Weather here?" {
+		t.Errorf("Expected the text of the user message, got %v", user["content"])
+	}
+	if parts := user["content_parts"].AsSlice(); len(parts) != 2 || bodyMap(parts[1])["url"].AsString() != "https://example.com/sky.jpg" {
+		t.Errorf("Expected the content parts of the user message, got %v", user["content_parts"])
+	}
+
+	if tool := bodyMap(logger.records[2].record.Body()); tool["id"].AsString() != "call_1" {
+		t.Errorf("Expected the tool call ID of the tool message, got %v", tool["id"])
+	}
+
+	choice := bodyMap(logger.records[3].record.Body())
+	if choice["finish_reason"].AsString() != "tool_calls" || choice["index"].AsInt64() != 0 {
+		t.Errorf("Unexpected choice: %v", choice)
+	}
+	message := bodyMap(choice["message"])
+	if message["role"].AsString() != "model" {
+		t.Errorf("Expected the role of the choice, got %v", message["role"])
+	}
+	toolCalls := message["tool_calls"].AsSlice()
+	if len(toolCalls) != 1 || bodyMap(bodyMap(toolCalls[0])["function"])["name"].AsString() != "get_forecast" {
+		t.Errorf("Expected the tool call of the choice, got %v", message["tool_calls"])
+	}
+}
@@ -0,0 +1,138 @@
+// Package llmhttp traces the HTTP calls of LLM SDKs that accept a request
+// middleware, such as openai-go and anthropic-sdk-go. It holds the plumbing
+// shared by their instrumentations, which only parse the bodies of the APIs
+// they trace; applications use the instrumentations instead.
+package llmhttp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	sdk "github.com/traceloop/go-openllmetry/traceloop-sdk"
+)
+
+// Endpoint parses the request and response bodies of a single API.
+type Endpoint interface {
+	Prompt(body []byte) (sdk.Prompt, error)
+	Completion(body []byte) (sdk.Completion, sdk.Usage, error)
+	// StreamDecoder returns a new decoder for the server-sent events of a
+	// streamed response.
+	StreamDecoder() StreamDecoder
+}
+
+// StreamDecoder converts the server-sent event payloads of a streamed
+// response into completion chunks.
+type StreamDecoder interface {
+	Chunks(data []byte) []sdk.StreamChunk
+}
+
+// Next sends a request to the next handler of the middleware chain.
+type Next = func(*http.Request) (*http.Response, error)
+
+// Middleware logs the calls to the endpoints returned by endpointFor as LLM
+// spans. Requests for which it returns nil are passed through untraced.
+func Middleware(traceloop *sdk.Traceloop, workflowAttrs sdk.WorkflowAttributes, endpointFor func(*http.Request) Endpoint) func(*http.Request, Next) (*http.Response, error) {
+	return func(req *http.Request, next Next) (*http.Response, error) {
+		endpoint := endpointFor(req)
+		if endpoint == nil {
+			return next(req)
+		}
+
+		body, err := readRequestBody(req)
+		if err != nil {
+			fmt.Printf("Failed to read request body: %v\n", err)
+			return next(req)
+		}
+
+		prompt, err := endpoint.Prompt(body)
+		if err != nil {
+			fmt.Printf("Failed to parse request body: %v\n", err)
+			return next(req)
+		}
+
+		ctx := req.Context()
+		llmSpan, err := traceloop.LogPrompt(ctx, prompt, workflowAttrs)
+		if err != nil {
+			return next(req)
+		}
+
+		resp, err := next(req)
+		if err != nil {
+			llmSpan.LogError(ctx, err)
+			return resp, err
+		}
+		if resp.StatusCode >= http.StatusBadRequest {
+			llmSpan.LogError(ctx, responseError(resp), sdk.WithStatusCode(resp.StatusCode))
+			return resp, nil
+		}
+
+		if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+			resp.Body = newStreamBody(resp.Body, endpoint.StreamDecoder(), llmSpan.NewStream(ctx))
+			return resp, nil
+		}
+
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(respBody))
+		if err != nil {
+			llmSpan.LogError(ctx, err)
+			return resp, nil
+		}
+
+		completion, usage, err := endpoint.Completion(respBody)
+		if err != nil {
+			LogParseError(ctx, llmSpan, err)
+			return resp, nil
+		}
+
+		llmSpan.LogCompletion(ctx, completion, usage)
+		return resp, nil
+	}
+}
+
+// LogParseError ends an LLM span whose response could not be parsed,
+// recording the parse error rather than an empty completion.
+func LogParseError(ctx context.Context, llmSpan sdk.LLMSpan, err error) {
+	llmSpan.LogError(ctx, fmt.Errorf("failed to parse response: %w", err))
+}
+
+// readRequestBody returns the request body while leaving it readable for the
+// next handler in the chain.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return io.ReadAll(body)
+	}
+
+	if req.Body == nil {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, err
+}
+
+// responseError builds the error of a failed response from its body, which
+// carries the provider's error type and message, while leaving the body
+// readable for the client.
+func responseError(resp *http.Response) error {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil || len(body) == 0 {
+		return errors.New(resp.Status)
+	}
+
+	return fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(body))
+}
@@ -0,0 +1,172 @@
+package llmhttp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	sdk "github.com/traceloop/go-openllmetry/traceloop-sdk"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// echoEndpoint reads the model from the request and the content of the
+// completion from the response, streamed or not.
+type echoEndpoint struct{}
+
+func (echoEndpoint) Prompt(body []byte) (sdk.Prompt, error) {
+	var request struct {
+		Model string `json:"model"`
+	}
+	err := json.Unmarshal(body, &request)
+	return sdk.Prompt{Vendor: "echo", Mode: "chat", Model: request.Model}, err
+}
+
+func (echoEndpoint) Completion(body []byte) (sdk.Completion, sdk.Usage, error) {
+	var response struct {
+		Content string `json:"content"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return sdk.Completion{}, sdk.Usage{}, err
+	}
+
+	return sdk.Completion{Messages: []sdk.Message{{Role: "assistant", Content: response.Content}}}, sdk.Usage{}, nil
+}
+
+func (echoEndpoint) StreamDecoder() StreamDecoder {
+	return echoDecoder{}
+}
+
+type echoDecoder struct{}
+
+func (echoDecoder) Chunks(data []byte) []sdk.StreamChunk {
+	return []sdk.StreamChunk{{Content: string(data)}}
+}
+
+func newTestMiddleware(t *testing.T) (func(*http.Request, Next) (*http.Response, error), *tracetest.InMemoryExporter) {
+	t.Helper()
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(server.Close)
+
+	exporter := tracetest.NewInMemoryExporter()
+	tl, err := sdk.NewClient(context.Background(), sdk.Config{
+		BaseURL:      server.URL,
+		Exporter:     exporter,
+		DisableBatch: true,
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	return Middleware(tl, sdk.WorkflowAttributes{}, func(req *http.Request) Endpoint {
+		if req.URL.Path != "/chat" {
+			return nil
+		}
+		return echoEndpoint{}
+	}), exporter
+}
+
+// respond returns a next handler that checks the request body is still
+// readable and answers with body.
+func respond(t *testing.T, contentType, body string) Next {
+	return func(req *http.Request) (*http.Response, error) {
+		if requestBody, _ := io.ReadAll(req.Body); len(requestBody) == 0 {
+			t.Error("Expected the request body to be readable by the next handler")
+		}
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {contentType}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}, nil
+	}
+}
+
+func newRequest(path string) *http.Request {
+	return httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"model":"echo-1"}`))
+}
+
+func TestMiddleware(t *testing.T) {
+	middleware, exporter := newTestMiddleware(t)
+
+	resp, err := middleware(newRequest("/chat"), respond(t, "application/json", `{"content":"Hello"}`))
+	if err != nil {
+		t.Fatalf("Middleware failed: %v", err)
+	}
+	if body, _ := io.ReadAll(resp.Body); string(body) != `{"content":"Hello"}` {
+		t.Errorf("Expected the response body to be readable by the client, got %s", body)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	for _, attr := range spans[0].Attributes {
+		if attr.Key == "llm.completions.0.content" && attr.Value.AsString() != "Hello" {
+			t.Errorf("Expected the completion to be logged, got %s", attr.Value.AsString())
+		}
+	}
+}
+
+func TestMiddlewareUntracedEndpoint(t *testing.T) {
+	middleware, exporter := newTestMiddleware(t)
+
+	if _, err := middleware(newRequest("/models"), respond(t, "application/json", `{}`)); err != nil {
+		t.Fatalf("Middleware failed: %v", err)
+	}
+	if spans := exporter.GetSpans(); len(spans) != 0 {
+		t.Errorf("Expected no span, got %d", len(spans))
+	}
+}
+
+func TestMiddlewareStream(t *testing.T) {
+	middleware, exporter := newTestMiddleware(t)
+
+	resp, err := middleware(newRequest("/chat"), respond(t, "text/event-stream", "data: Hel\n\ndata: lo\n\ndata: [DONE]\n\n"))
+	if err != nil {
+		t.Fatalf("Middleware failed: %v", err)
+	}
+	if len(exporter.GetSpans()) != 0 {
+		t.Fatal("Expected the span to be ended once the stream is read")
+	}
+	io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	attrs := make(map[string]interface{})
+	for _, attr := range spans[0].Attributes {
+		attrs[string(attr.Key)] = attr.Value.AsInterface()
+	}
+	if attrs["llm.completions.0.content"] != "Hello" || attrs["llm.is_streaming"] != true {
+		t.Errorf("Expected the streamed completion to be logged, got %v", attrs)
+	}
+}
+
+func TestMiddlewareParseError(t *testing.T) {
+	middleware, exporter := newTestMiddleware(t)
+
+	if _, err := middleware(newRequest("/chat"), respond(t, "application/json", `not json`)); err != nil {
+		t.Fatalf("Middleware failed: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	if spans[0].Status.Code != codes.Error || !strings.Contains(spans[0].Status.Description, "failed to parse response") {
+		t.Errorf("Expected the span to record the parse error, got %v", spans[0].Status)
+	}
+	for _, attr := range spans[0].Attributes {
+		if attr.Key == "llm.usage.total_tokens" {
+			t.Error("Expected no usage to be logged for an unparsed response")
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package llmhttp
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	sdk "github.com/traceloop/go-openllmetry/traceloop-sdk"
+)
+
+// streamBody passes a server-sent event stream through to the SDK while
+// adding the chunks of each event to a completion stream, which logs the
+// completion once the body is drained or closed.
+type streamBody struct {
+	io.ReadCloser
+	decoder    StreamDecoder
+	completion *sdk.CompletionStream
+	pending    []byte
+}
+
+func newStreamBody(body io.ReadCloser, decoder StreamDecoder, completion *sdk.CompletionStream) *streamBody {
+	return &streamBody{
+		ReadCloser: body,
+		decoder:    decoder,
+		completion: completion,
+	}
+}
+
+func (body *streamBody) Read(p []byte) (int, error) {
+	n, err := body.ReadCloser.Read(p)
+	if n > 0 {
+		body.feed(p[:n])
+	}
+	if errors.Is(err, io.EOF) {
+		body.completion.Close()
+	} else if err != nil {
+		body.completion.CloseWithError(err)
+	}
+
+	return n, err
+}
+
+func (body *streamBody) Close() error {
+	body.completion.Close()
+	return body.ReadCloser.Close()
+}
+
+func (body *streamBody) feed(chunk []byte) {
+	body.pending = append(body.pending, chunk...)
+
+	for {
+		i := bytes.IndexByte(body.pending, '\n')
+		if i < 0 {
+			return
+		}
+
+		line := bytes.TrimRight(body.pending[:i], "\r")
+		body.pending = body.pending[i+1:]
+
+		data, ok := bytes.CutPrefix(line, []byte("data:"))
+		if !ok {
+			continue
+		}
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 || bytes.Equal(data, []byte("[DONE]")) {
+			continue
+		}
+
+		for _, chunk := range body.decoder.Chunks(data) {
+			body.completion.AddChunk(chunk)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package traceloop
+
+import (
+	"context"
+	"strings"
+
+	semconvai "github.com/traceloop/go-openllmetry/semconv-ai"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// ModelPrice is the price of a model in USD. Token prices are per million
+// tokens. Cached and cache creation tokens are billed at the input price when
+// their own price is not set.
+type ModelPrice struct {
+	InputTokens         float64 `json:"input_tokens"`
+	OutputTokens        float64 `json:"output_tokens"`
+	CachedInputTokens   float64 `json:"cached_input_tokens,omitempty"`
+	CacheCreationTokens float64 `json:"cache_creation_tokens,omitempty"`
+	Image               float64 `json:"image,omitempty"`
+	AudioSecond         float64 `json:"audio_second,omitempty"`
+}
+
+// Cost returns the cost in USD of a call with the given usage.
+func (price ModelPrice) Cost(usage Usage) float64 {
+	cachedPrice := price.CachedInputTokens
+	if cachedPrice == 0 {
+		cachedPrice = price.InputTokens
+	}
+	cacheCreationPrice := price.CacheCreationTokens
+	if cacheCreationPrice == 0 {
+		cacheCreationPrice = price.InputTokens
+	}
+
+	// Prompt tokens include the cached and cache creation tokens.
+	inputTokens := max(usage.PromptTokens-usage.CacheReadInputTokens-usage.CacheCreationInputTokens, 0)
+
+	tokensCost := float64(inputTokens)*price.InputTokens +
+		float64(usage.CacheReadInputTokens)*cachedPrice +
+		float64(usage.CacheCreationInputTokens)*cacheCreationPrice +
+		float64(usage.CompletionTokens)*price.OutputTokens
+
+	return tokensCost/1_000_000 +
+		float64(usage.ImageCount)*price.Image +
+		usage.AudioSeconds*price.AudioSecond
+}
+
+// PriceTable maps model names to their price.
+type PriceTable map[string]ModelPrice
+
+// Lookup returns the price of model. Models without an exact match take the
+// price of the longest model name they start with, so dated snapshots such as
+// gpt-4o-2024-08-06 are priced as gpt-4o. Vendor prefixes such as
+// "anthropic." on Bedrock or "models/" on Gemini are ignored.
+func (table PriceTable) Lookup(model string) (ModelPrice, bool) {
+	if model == "" {
+		return ModelPrice{}, false
+	}
+	if price, ok := lookupModel(table, model); ok {
+		return price, true
+	}
+
+	return lookupModel(table, trimModelPrefix(model))
+}
+
+// lookupModel returns the value of model in values, or else the value of the
+// longest model name it starts with.
+func lookupModel[V any](values map[string]V, model string) (V, bool) {
+	if value, ok := values[model]; ok {
+		return value, true
+	}
+
+	var match string
+	for name := range values {
+		if len(name) > len(match) && strings.HasPrefix(model, name) {
+			match = name
+		}
+	}
+	if match == "" {
+		var zero V
+		return zero, false
+	}
+
+	return values[match], true
+}
+
+// trimModelPrefix removes the path and the dotted vendor and region prefixes
+// of a model name, e.g. us.anthropic.claude-sonnet-4-20250514-v1:0.
+func trimModelPrefix(model string) string {
+	model = model[strings.LastIndex(model, "/")+1:]
+	if dash := strings.Index(model, "-"); dash > 0 {
+		model = model[strings.LastIndex(model[:dash], ".")+1:]
+	}
+
+	return model
+}
+
+// DefaultPrices returns a copy of the built-in price table, with the public
+// list prices of common OpenAI, Anthropic and Gemini models. Prices change:
+// override them with PricingConfig.Prices.
+func DefaultPrices() PriceTable {
+	prices := make(PriceTable, len(defaultPrices))
+	for model, price := range defaultPrices {
+		prices[model] = price
+	}
+
+	return prices
+}
+
+var defaultPrices = PriceTable{
+	// OpenAI
+	"gpt-5":                  {InputTokens: 1.25, CachedInputTokens: 0.125, OutputTokens: 10},
+	"gpt-5-mini":             {InputTokens: 0.25, CachedInputTokens: 0.025, OutputTokens: 2},
+	"gpt-5-nano":             {InputTokens: 0.05, CachedInputTokens: 0.005, OutputTokens: 0.4},
+	"gpt-4.1":                {InputTokens: 2, CachedInputTokens: 0.5, OutputTokens: 8},
+	"gpt-4.1-mini":           {InputTokens: 0.4, CachedInputTokens: 0.1, OutputTokens: 1.6},
+	"gpt-4.1-nano":           {InputTokens: 0.1, CachedInputTokens: 0.025, OutputTokens: 0.4},
+	"gpt-4o":                 {InputTokens: 2.5, CachedInputTokens: 1.25, OutputTokens: 10},
+	"gpt-4o-mini":            {InputTokens: 0.15, CachedInputTokens: 0.075, OutputTokens: 0.6},
+	"gpt-4-turbo":            {InputTokens: 10, OutputTokens: 30},
+	"gpt-4":                  {InputTokens: 30, OutputTokens: 60},
+	"gpt-3.5-turbo":          {InputTokens: 0.5, OutputTokens: 1.5},
+	"o1":                     {InputTokens: 15, CachedInputTokens: 7.5, OutputTokens: 60},
+	"o1-mini":                {InputTokens: 1.1, CachedInputTokens: 0.55, OutputTokens: 4.4},
+	"o3":                     {InputTokens: 2, CachedInputTokens: 0.5, OutputTokens: 8},
+	"o3-mini":                {InputTokens: 1.1, CachedInputTokens: 0.55, OutputTokens: 4.4},
+	"o4-mini":                {InputTokens: 1.1, CachedInputTokens: 0.275, OutputTokens: 4.4},
+	"text-embedding-3-small": {InputTokens: 0.02},
+	"text-embedding-3-large": {InputTokens: 0.13},
+	"text-embedding-ada-002": {InputTokens: 0.1},
+	"dall-e-3":               {Image: 0.04},
+	"dall-e-2":               {Image: 0.02},
+	"whisper-1":              {AudioSecond: 0.0001},
+
+	// Anthropic
+	"claude-opus-4-5":   {InputTokens: 5, CachedInputTokens: 0.5, CacheCreationTokens: 6.25, OutputTokens: 25},
+	"claude-opus-4":     {InputTokens: 15, CachedInputTokens: 1.5, CacheCreationTokens: 18.75, OutputTokens: 75},
+	"claude-sonnet-4":   {InputTokens: 3, CachedInputTokens: 0.3, CacheCreationTokens: 3.75, OutputTokens: 15},
+	"claude-haiku-4-5":  {InputTokens: 1, CachedInputTokens: 0.1, CacheCreationTokens: 1.25, OutputTokens: 5},
+	"claude-3-7-sonnet": {InputTokens: 3, CachedInputTokens: 0.3, CacheCreationTokens: 3.75, OutputTokens: 15},
+	"claude-3-5-sonnet": {InputTokens: 3, CachedInputTokens: 0.3, CacheCreationTokens: 3.75, OutputTokens: 15},
+	"claude-3-5-haiku":  {InputTokens: 0.8, CachedInputTokens: 0.08, CacheCreationTokens: 1, OutputTokens: 4},
+	"claude-3-opus":     {InputTokens: 15, CachedInputTokens: 1.5, CacheCreationTokens: 18.75, OutputTokens: 75},
+	"claude-3-haiku":    {InputTokens: 0.25, CachedInputTokens: 0.03, CacheCreationTokens: 0.3, OutputTokens: 1.25},
+
+	// Google
+	"gemini-2.5-pro":        {InputTokens: 1.25, CachedInputTokens: 0.31, OutputTokens: 10},
+	"gemini-2.5-flash":      {InputTokens: 0.3, CachedInputTokens: 0.075, OutputTokens: 2.5},
+	"gemini-2.5-flash-lite": {InputTokens: 0.1, CachedInputTokens: 0.025, OutputTokens: 0.4},
+	"gemini-2.0-flash":      {InputTokens: 0.1, CachedInputTokens: 0.025, OutputTokens: 0.4},
+	"gemini-1.5-pro":        {InputTokens: 1.25, OutputTokens: 5},
+	"gemini-1.5-flash":      {InputTokens: 0.075, OutputTokens: 0.3},
+}
+
+// pricing computes the cost of LLM calls and records it on the cost metric.
+type pricing struct {
+	prices PriceTable
+	cost   metric.Float64Counter
+}
+
+func newPricing(config PricingConfig, meter metric.Meter) (*pricing, error) {
+	prices := DefaultPrices()
+	for model, price := range config.Prices {
+		prices[model] = price
+	}
+
+	cost, err := meter.Float64Counter(
+		string(semconvai.LLMUsageCost),
+		metric.WithUnit("USD"),
+		metric.WithDescription("Cost of the LLM calls"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pricing{prices: prices, cost: cost}, nil
+}
+
+// unbilledModes are the request types providers do not charge for, although
+// they report token counts.
+var unbilledModes = map[string]bool{
+	"count_tokens": true,
+	"moderation":   true,
+}
+
+// recordCost sets the cost of the call on the LLM span and adds it to the cost
+// metric, along with the span's vendor, models, workflow and association
+// properties. Calls without usage, unbilled calls and calls to an unknown
+// model are not priced.
+func (llmSpan *LLMSpan) recordCost(ctx context.Context, responseModel string, usage Usage) {
+	if llmSpan.pricing == nil || usage == (Usage{}) || unbilledModes[llmSpan.mode] {
+		return
+	}
+
+	price, ok := llmSpan.pricing.prices.Lookup(responseModel)
+	if !ok {
+		price, ok = llmSpan.pricing.prices.Lookup(llmSpan.model)
+	}
+	if !ok {
+		return
+	}
+
+	cost := price.Cost(usage)
+	llmSpan.span.SetAttributes(semconvai.LLMUsageCost.Float64(cost))
+
+	attrs := append([]attribute.KeyValue{semconvai.LLMResponseModel.String(responseModel)}, llmSpan.metricAttrs...)
+	llmSpan.pricing.cost.Add(ctx, cost, metric.WithAttributes(attrs...))
+}
@@ -0,0 +1,138 @@
+package traceloop
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestModelPriceCost(t *testing.T) {
+	price := ModelPrice{InputTokens: 3, OutputTokens: 15, CachedInputTokens: 0.3, CacheCreationTokens: 3.75}
+
+	cost := price.Cost(Usage{
+		PromptTokens:             1_500_000,
+		CompletionTokens:         100_000,
+		CacheReadInputTokens:     300_000,
+		CacheCreationInputTokens: 200_000,
+	})
+	// 1M input tokens, 300k cached, 200k written to the cache and 100k output.
+	expected := 3 + 0.09 + 0.75 + 1.5
+	if math.Abs(cost-expected) > 1e-9 {
+		t.Errorf("Expected a cost of %v, got %v", expected, cost)
+	}
+
+	if cost := (ModelPrice{Image: 0.04, AudioSecond: 0.0001}).Cost(Usage{ImageCount: 2, AudioSeconds: 60}); math.Abs(cost-0.086) > 1e-9 {
+		t.Errorf("Expected a cost of 0.086, got %v", cost)
+	}
+}
+
+func TestPriceTableLookup(t *testing.T) {
+	prices := DefaultPrices()
+
+	tests := map[string]string{
+		"gpt-4o":                 "gpt-4o",
+		"gpt-4o-2024-08-06":      "gpt-4o",
+		"gpt-4o-mini-2024-07-18": "gpt-4o-mini",
+		"us.anthropic.claude-sonnet-4-20250514-v1:0": "claude-sonnet-4",
+		"models/gemini-2.5-flash-lite":               "gemini-2.5-flash-lite",
+	}
+	for model, expected := range tests {
+		price, ok := prices.Lookup(model)
+		if !ok || price != prices[expected] {
+			t.Errorf("Expected %s to be priced as %s, got %v", model, expected, price)
+		}
+	}
+
+	if _, ok := prices.Lookup("llama3.2"); ok {
+		t.Error("Expected no price for an unknown model")
+	}
+}
+
+func TestCostRecorded(t *testing.T) {
+	tl, exporter := newTestTraceloop(t)
+	reader := sdkmetric.NewManualReader()
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	var err error
+	tl.pricing, err = newPricing(PricingConfig{
+		Prices: PriceTable{"gpt-4o": {InputTokens: 2, OutputTokens: 10}},
+	}, meterProvider.Meter("test"))
+	if err != nil {
+		t.Fatalf("newPricing failed: %v", err)
+	}
+
+	ctx := context.Background()
+	llmSpan, _ := tl.LogPrompt(ctx, Prompt{Vendor: "openai", Mode: "chat", Model: "gpt-4o"}, WorkflowAttributes{
+		Name:                  "support",
+		AssociationProperties: map[string]string{"user_id": "user-1"},
+	})
+	llmSpan.LogCompletion(ctx, Completion{Model: "gpt-4o-2024-08-06"}, Usage{
+		PromptTokens:     1000,
+		CompletionTokens: 100,
+		TotalTokens:      1100,
+	})
+
+	_, attrs := exportedSpan(t, exporter)
+	if cost, _ := attrs["llm.usage.cost"].(float64); math.Abs(cost-0.003) > 1e-9 {
+		t.Errorf("Expected a cost of 0.003 on the span, got %v", attrs["llm.usage.cost"])
+	}
+
+	var metrics metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &metrics); err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if len(metrics.ScopeMetrics) != 1 || len(metrics.ScopeMetrics[0].Metrics) != 1 {
+		t.Fatalf("Expected the cost metric, got %v", metrics.ScopeMetrics)
+	}
+	sum, ok := metrics.ScopeMetrics[0].Metrics[0].Data.(metricdata.Sum[float64])
+	if !ok || len(sum.DataPoints) != 1 {
+		t.Fatalf("Expected a single cost data point, got %v", metrics.ScopeMetrics[0].Metrics[0].Data)
+	}
+
+	point := sum.DataPoints[0]
+	if math.Abs(point.Value-0.003) > 1e-9 {
+		t.Errorf("Expected a cost of 0.003 on the metric, got %v", point.Value)
+	}
+	expected := map[attribute.Key]string{
+		"llm.vendor":                               "openai",
+		"llm.request.model":                        "gpt-4o",
+		"llm.response.model":                       "gpt-4o-2024-08-06",
+		"traceloop.workflow.name":                  "support",
+		"traceloop.association.properties.user_id": "user-1",
+	}
+	for key, value := range expected {
+		if got, _ := point.Attributes.Value(key); got.AsString() != value {
+			t.Errorf("Metric attribute %s: expected %v, got %v", key, value, got.AsString())
+		}
+	}
+}
+
+func TestCostNotRecordedForUnknownModel(t *testing.T) {
+	tl, exporter := newTestTraceloop(t)
+	tl.pricing, _ = newPricing(PricingConfig{}, sdkmetric.NewMeterProvider().Meter("test"))
+
+	ctx := context.Background()
+	llmSpan, _ := tl.LogPrompt(ctx, Prompt{Vendor: "ollama", Mode: "chat", Model: "llama3.2"}, WorkflowAttributes{})
+	llmSpan.LogCompletion(ctx, Completion{Model: "llama3.2"}, Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15})
+
+	if _, attrs := exportedSpan(t, exporter); attrs["llm.usage.cost"] != nil {
+		t.Errorf("Expected no cost for an unknown model, got %v", attrs["llm.usage.cost"])
+	}
+}
+
+func TestCostNotRecordedForUnbilledMode(t *testing.T) {
+	tl, exporter := newTestTraceloop(t)
+	tl.pricing, _ = newPricing(PricingConfig{}, sdkmetric.NewMeterProvider().Meter("test"))
+
+	ctx := context.Background()
+	llmSpan, _ := tl.LogPrompt(ctx, Prompt{Vendor: "gemini", Mode: "count_tokens", Model: "gemini-2.5-flash"}, WorkflowAttributes{})
+	llmSpan.LogCompletion(ctx, Completion{Model: "gemini-2.5-flash"}, Usage{PromptTokens: 10, TotalTokens: 10})
+
+	if _, attrs := exportedSpan(t, exporter); attrs["llm.usage.cost"] != nil {
+		t.Errorf("Expected no cost for counting tokens, got %v", attrs["llm.usage.cost"])
+	}
+}
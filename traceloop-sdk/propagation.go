@@ -0,0 +1,109 @@
+package traceloop
+
+import (
+	"context"
+	"net/http"
+
+	semconvai "github.com/traceloop/go-openllmetry/semconv-ai"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// workflowPropagator propagates the trace context, and the workflow and the
+// association properties as W3C Baggage.
+var workflowPropagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// InjectWorkflow injects the trace context carried by ctx into carrier, along
+// with the name and entity path of the workflow it carries and the association
+// properties, so a downstream service can continue the workflow. Association
+// properties may identify users, so only inject them into requests to
+// services you trust.
+func InjectWorkflow(ctx context.Context, carrier propagation.TextMapCarrier) {
+	bag := associationPropertiesBaggage(ctx, baggage.FromContext(ctx))
+	if workflow := WorkflowFromContext(ctx); workflow != nil {
+		bag = setBaggageMember(bag, string(semconvai.TraceloopWorkflowName), workflow.Attributes.Name)
+		if path := entityPath(ctx); path != "" {
+			bag = setBaggageMember(bag, string(semconvai.TraceloopEntityPath), path)
+		} else {
+			bag = bag.DeleteMember(string(semconvai.TraceloopEntityPath))
+		}
+	}
+
+	workflowPropagator.Inject(baggage.ContextWithBaggage(ctx, bag), carrier)
+}
+
+// ExtractWorkflow returns a copy of ctx carrying the trace context and the
+// association properties extracted from carrier. When the upstream service
+// injected a workflow, the context also carries it, so tasks, tools and LLM
+// calls of this service are recorded under the upstream span, as part of the
+// same workflow. The workflow is ended by the service that started it.
+func (instance *Traceloop) ExtractWorkflow(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	ctx = workflowPropagator.Extract(ctx, carrier)
+
+	bag := baggage.FromContext(ctx)
+	ctx = WithAssociationProperties(ctx, associationPropertiesFromBaggage(bag))
+	name := bag.Member(string(semconvai.TraceloopWorkflowName)).Value()
+	if name == "" || !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+
+	workflow := &Workflow{
+		sdk: instance,
+		Attributes: WorkflowAttributes{
+			Name:                  name,
+			AssociationProperties: AssociationPropertiesFromContext(ctx),
+		},
+	}
+	ctx = context.WithValue(ctx, entityPathKey{}, bag.Member(string(semconvai.TraceloopEntityPath)).Value())
+	ctx = context.WithValue(ctx, taskKey{}, (*Task)(nil))
+	workflow.ctx = context.WithValue(ctx, workflowKey{}, workflow)
+
+	return workflow.ctx
+}
+
+// HTTPTransport returns base wrapped to propagate the workflow carried by the
+// context of each request to one of hosts, with InjectWorkflow. Requests to
+// other hosts, e.g. to LLM vendors or other third parties, are sent as is, so
+// the association properties do not leak to them. Hosts are matched against
+// the host of the request URL, with or without its port. A nil base stands
+// for http.DefaultTransport.
+func HTTPTransport(base http.RoundTripper, hosts ...string) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	allowed := make(map[string]bool, len(hosts))
+	for _, host := range hosts {
+		allowed[host] = true
+	}
+
+	return &workflowTransport{base: base, hosts: allowed}
+}
+
+type workflowTransport struct {
+	base  http.RoundTripper
+	hosts map[string]bool
+}
+
+func (t *workflowTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.hosts[req.URL.Host] && !t.hosts[req.URL.Hostname()] {
+		return t.base.RoundTrip(req)
+	}
+
+	// A RoundTripper must not modify the request it is given.
+	req = req.Clone(req.Context())
+	InjectWorkflow(req.Context(), propagation.HeaderCarrier(req.Header))
+
+	return t.base.RoundTrip(req)
+}
+
+// HTTPMiddleware returns next wrapped to continue the workflow propagated by
+// the client of each request, with ExtractWorkflow. Handlers get it with
+// WorkflowFromContext(r.Context()).
+func (instance *Traceloop) HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := instance.ExtractWorkflow(r.Context(), propagation.HeaderCarrier(r.Header))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,122 @@
+package traceloop
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestHTTPWorkflowPropagation(t *testing.T) {
+	tl, exporter := newTestTraceloop(t)
+
+	server := httptest.NewServer(tl.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		workflow := WorkflowFromContext(r.Context())
+		if workflow == nil {
+			t.Error("Expected the workflow to be continued by the server")
+			return
+		}
+		if workflow.Attributes.Name != "support" {
+			t.Errorf("Expected the workflow name to be propagated, got %q", workflow.Attributes.Name)
+		}
+
+		// Tasks of the downstream service are started from the request context.
+		task := WrapTask(tl, "search", func(ctx context.Context, query string) (string, error) {
+			return "found", nil
+		})
+		task(r.Context(), r.URL.Query().Get("q"))
+		workflow.End()
+	})))
+	defer server.Close()
+
+	wf := tl.NewWorkflow(context.Background(), WorkflowAttributes{
+		Name:                  "support",
+		AssociationProperties: map[string]string{"user_id": "user-1"},
+	})
+	task := wf.NewTask("retrieve")
+
+	serverURL, _ := url.Parse(server.URL)
+	client := http.Client{Transport: HTTPTransport(nil, serverURL.Host)}
+	req, _ := http.NewRequestWithContext(task.Context(), http.MethodGet, server.URL+"?q=weather", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if req.Header.Get("traceparent") != "" {
+		t.Error("Expected the transport to leave the original request untouched")
+	}
+	task.End()
+	wf.End()
+
+	spans := make(map[string]tracetest.SpanStub)
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+	if len(spans) != 3 {
+		t.Fatalf("Expected 3 spans, got %d", len(spans))
+	}
+
+	downstream := spans["search.task"]
+	if downstream.SpanContext.TraceID() != spans["support.workflow"].SpanContext.TraceID() {
+		t.Error("Expected the downstream task to be part of the workflow trace")
+	}
+	if downstream.Parent.SpanID() != spans["retrieve.task"].SpanContext.SpanID() {
+		t.Error("Expected the downstream task to be a child of the upstream task")
+	}
+
+	expected := map[string]interface{}{
+		"traceloop.workflow.name":                  "support",
+		"traceloop.entity.path":                    "retrieve.search",
+		"traceloop.association.properties.user_id": "user-1",
+	}
+	attrs := spanAttributeMap(downstream)
+	for key, value := range expected {
+		if attrs[key] != value {
+			t.Errorf("Attribute %s: expected %v, got %v", key, value, attrs[key])
+		}
+	}
+}
+
+func TestHTTPTransportOtherHost(t *testing.T) {
+	tl, _ := newTestTraceloop(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("traceparent") != "" || r.Header.Get("baggage") != "" {
+			t.Errorf("Expected no workflow to be sent to a host not allowed, got %v", r.Header)
+		}
+	}))
+	defer server.Close()
+
+	ctx := WithAssociationProperties(context.Background(), map[string]string{"user_id": "user-1"})
+	wf := tl.NewWorkflow(ctx, WorkflowAttributes{Name: "support"})
+	defer wf.End()
+
+	client := http.Client{Transport: HTTPTransport(nil, "internal.example.com")}
+	req, _ := http.NewRequestWithContext(wf.Context(), http.MethodGet, server.URL, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestHTTPMiddlewareWithoutWorkflow(t *testing.T) {
+	tl, _ := newTestTraceloop(t)
+
+	var called bool
+	handler := tl.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		if WorkflowFromContext(r.Context()) != nil {
+			t.Error("Expected no workflow without propagation headers")
+		}
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if !called {
+		t.Error("Expected the handler to be called")
+	}
+}
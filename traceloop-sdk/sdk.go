@@ -11,7 +11,11 @@ import (
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/sdk/trace"
 	apitrace "go.opentelemetry.io/otel/trace"
 
@@ -26,11 +30,25 @@ type Traceloop struct {
 	promptRegistry model.PromptRegistry
 	registryMutex  sync.RWMutex
 	tracerProvider *trace.TracerProvider
+	pricing        *pricing
+	tokenizers     map[string]Tokenizer
+	logger         otellog.Logger
 	http.Client
 }
 
 type LLMSpan struct {
-	span apitrace.Span
+	span          apitrace.Span
+	startTime     time.Time
+	binaryContent BinaryContentConfig
+	pricing       *pricing
+	mode          string
+	model         string
+	metricAttrs   []attribute.KeyValue
+	tokenizer     Tokenizer
+	prompt        *Prompt
+	semconv       SemconvMode
+	logger        otellog.Logger
+	system        string
 }
 
 func NewClient(ctx context.Context, config Config) (*Traceloop, error) {
@@ -77,17 +95,46 @@ func (instance *Traceloop) initialize(ctx context.Context) error {
 		return err
 	}
 
+	instance.tokenizers = newTokenizers(instance.config.TokenCounting)
+
+	if instance.config.Messages == MessagesEvents {
+		instance.logger = instance.getLogger()
+	}
+
+	if !instance.config.Pricing.Disabled {
+		instance.pricing, err = newPricing(instance.config.Pricing, instance.getMeter())
+		if err != nil {
+			return fmt.Errorf("create cost metric: %w", err)
+		}
+	}
+
 	return nil
 }
 
-func setMessagesAttribute(span apitrace.Span, prefix string, messages []Message) {
+func setMessagesAttribute(ctx context.Context, span apitrace.Span, prefix string, messages []Message, binaryContent BinaryContentConfig) {
 	for _, message := range messages {
 		attrsPrefix := fmt.Sprintf("%s.%d", prefix, message.Index)
+		content := message.Content
+		if content == "" {
+			content = partsText(message.ContentParts)
+		}
 		span.SetAttributes(
-			attribute.String(attrsPrefix+".content", message.Content),
+			attribute.String(attrsPrefix+".content", content),
 			attribute.String(attrsPrefix+".role", message.Role),
 		)
 
+		if len(message.ContentParts) > 0 {
+			setContentPartsAttribute(ctx, span, attrsPrefix, message.ContentParts, binaryContent)
+		}
+
+		if message.FinishReason != "" {
+			span.SetAttributes(attribute.String(attrsPrefix+".finish_reason", message.FinishReason))
+		}
+
+		if message.ToolCallID != "" {
+			span.SetAttributes(attribute.String(attrsPrefix+".tool_call_id", message.ToolCallID))
+		}
+
 		if len(message.ToolCalls) > 0 {
 			setToolCallsAttribute(span, attrsPrefix, message.ToolCalls)
 		}
@@ -107,6 +154,56 @@ func setToolCallsAttribute(span apitrace.Span, messagePrefix string, toolCalls [
 	}
 }
 
+// setRequestParametersAttribute records the sampling and output settings of
+// a prompt. Parameters the caller did not set, nil or left to their zero
+// value, are omitted.
+func setRequestParametersAttribute(span apitrace.Span, prompt Prompt) {
+	var attrs []attribute.KeyValue
+	if prompt.Temperature != nil {
+		attrs = append(attrs, semconvai.LLMTemperature.Float64(float64(*prompt.Temperature)))
+	}
+	if prompt.TopP != nil {
+		attrs = append(attrs, semconvai.LLMTopP.Float64(float64(*prompt.TopP)))
+	}
+	if len(prompt.Stop) > 0 {
+		attrs = append(attrs, semconvai.LLMChatStopSequence.StringSlice(prompt.Stop))
+	}
+	if prompt.FrequencyPenalty != nil {
+		attrs = append(attrs, semconvai.LLMFrequencyPenalty.Float64(float64(*prompt.FrequencyPenalty)))
+	}
+	if prompt.PresencePenalty != nil {
+		attrs = append(attrs, semconvai.LLMPresencePenalty.Float64(float64(*prompt.PresencePenalty)))
+	}
+	if prompt.MaxTokens != 0 {
+		attrs = append(attrs, semconvai.LLMRequestMaxTokens.Int(prompt.MaxTokens))
+	}
+	if prompt.Seed != nil {
+		attrs = append(attrs, semconvai.LLMRequestSeed.Int(*prompt.Seed))
+	}
+	if prompt.N != 0 {
+		attrs = append(attrs, semconvai.LLMRequestN.Int(prompt.N))
+	}
+	if prompt.ResponseFormat != "" {
+		attrs = append(attrs, semconvai.LLMRequestResponseFormat.String(prompt.ResponseFormat))
+	}
+	if prompt.ToolChoice != "" {
+		attrs = append(attrs, semconvai.LLMRequestToolChoice.String(prompt.ToolChoice))
+	}
+	if prompt.User != "" {
+		attrs = append(attrs, semconvai.LLMUser.String(prompt.User))
+	}
+	if len(prompt.LogitBias) > 0 {
+		logitBiasJSON, err := json.Marshal(prompt.LogitBias)
+		if err == nil {
+			attrs = append(attrs, semconvai.LLMRequestLogitBias.String(string(logitBiasJSON)))
+		} else {
+			fmt.Printf("Failed to marshal logit bias: %v\n", err)
+		}
+	}
+
+	span.SetAttributes(attrs...)
+}
+
 func setToolsAttribute(span apitrace.Span, tools []Tool) {
 	if len(tools) == 0 {
 		return
@@ -144,33 +241,104 @@ func (instance *Traceloop) getTracer() apitrace.Tracer {
 	return (*instance.tracerProvider).Tracer(instance.tracerName())
 }
 
-// New workflow-based API
-func (instance *Traceloop) LogPrompt(ctx context.Context, prompt Prompt, workflowAttrs WorkflowAttributes) (LLMSpan, error) {
-	spanName := fmt.Sprintf("%s.%s", prompt.Vendor, prompt.Mode)
-	_, span := instance.getTracer().Start(ctx, spanName)
+func (instance *Traceloop) getMeter() metric.Meter {
+	meterProvider := instance.config.MeterProvider
+	if meterProvider == nil {
+		meterProvider = otel.GetMeterProvider()
+	}
+
+	return meterProvider.Meter(instance.tracerName())
+}
+
+func (instance *Traceloop) getLogger() otellog.Logger {
+	loggerProvider := instance.config.LoggerProvider
+	if loggerProvider == nil {
+		loggerProvider = global.GetLoggerProvider()
+	}
+
+	return loggerProvider.Logger(instance.tracerName())
+}
+
+// startLLMSpan starts the span of an LLM call, named after the vendor and the
+// request type, with the attributes shared by every request type. Empty
+// workflow attributes are taken from the workflow carried by ctx, if any.
+func (instance *Traceloop) startLLMSpan(ctx context.Context, vendor, mode, model string, workflowAttrs WorkflowAttributes) LLMSpan {
+	if workflowAttrs.Name == "" && len(workflowAttrs.AssociationProperties) == 0 {
+		if workflow := WorkflowFromContext(ctx); workflow != nil {
+			workflowAttrs = workflow.Attributes
+		}
+	}
+
+	spanName := fmt.Sprintf("%s.%s", vendor, mode)
+	var span apitrace.Span
+	_, span = instance.getTracer().Start(ctx, spanName)
+	if instance.config.Semconv != SemconvLegacy {
+		span = semconvSpan{Span: span, mode: instance.config.Semconv}
+	}
 
 	attrs := []attribute.KeyValue{
-		semconvai.LLMVendor.String(prompt.Vendor),
-		semconvai.LLMRequestModel.String(prompt.Model),
-		semconvai.LLMRequestType.String(prompt.Mode),
+		semconvai.LLMVendor.String(vendor),
+		semconvai.LLMRequestModel.String(model),
+		semconvai.LLMRequestType.String(mode),
 		semconvai.TraceloopWorkflowName.String(workflowAttrs.Name),
 	}
 
-	// Add association properties if provided
-	for key, value := range workflowAttrs.AssociationProperties {
-		attrs = append(attrs, attribute.String("traceloop.association.properties."+key, value))
+	if path := entityPath(ctx); path != "" {
+		attrs = append(attrs, semconvai.TraceloopEntityPath.String(path))
 	}
 
+	// Add the association properties carried by ctx and those provided
+	associationAttrs := associationPropertiesAttributes(ctx, workflowAttrs.AssociationProperties)
+	attrs = append(attrs, associationAttrs...)
+
 	span.SetAttributes(attrs...)
-	setMessagesAttribute(span, "llm.prompts", prompt.Messages)
-	setToolsAttribute(span, prompt.Tools)
 
 	return LLMSpan{
-		span: span,
-	}, nil
+		span:          span,
+		startTime:     time.Now(),
+		binaryContent: instance.config.BinaryContent,
+		pricing:       instance.pricing,
+		mode:          mode,
+		model:         model,
+		semconv:       instance.config.Semconv,
+		logger:        instance.logger,
+		system:        genAISystem(vendor),
+		metricAttrs: append([]attribute.KeyValue{
+			semconvai.LLMVendor.String(vendor),
+			semconvai.LLMRequestModel.String(model),
+			semconvai.LLMRequestType.String(mode),
+			semconvai.TraceloopWorkflowName.String(workflowAttrs.Name),
+		}, associationAttrs...),
+	}
 }
 
+// New workflow-based API. When workflowAttrs is empty, the workflow name and
+// association properties are those of the workflow carried by ctx.
+func (instance *Traceloop) LogPrompt(ctx context.Context, prompt Prompt, workflowAttrs WorkflowAttributes) (LLMSpan, error) {
+	llmSpan := instance.startLLMSpan(ctx, prompt.Vendor, prompt.Mode, prompt.Model, workflowAttrs)
+	setRequestParametersAttribute(llmSpan.span, prompt)
+	llmSpan.logPromptMessages(ctx, prompt.Messages)
+	setToolsAttribute(llmSpan.span, prompt.Tools)
+
+	if llmSpan.tokenizer = instance.tokenizer(prompt.Model); llmSpan.tokenizer != nil {
+		llmSpan.prompt = &prompt
+	}
+
+	return llmSpan, nil
+}
+
+// SetAttributes records additional, vendor specific attributes on the LLM
+// span. It must be called before LogCompletion, which ends the span.
+func (llmSpan *LLMSpan) SetAttributes(attrs ...attribute.KeyValue) {
+	llmSpan.span.SetAttributes(attrs...)
+}
+
+// LogCompletion records the completion and the token usage, and ends the LLM
+// span. When the usage is empty, it is estimated with the tokenizer of the
+// model, if any.
 func (llmSpan *LLMSpan) LogCompletion(ctx context.Context, completion Completion, usage Usage) error {
+	usage = llmSpan.logEstimatedUsage(completion, usage)
+
 	llmSpan.span.SetAttributes(
 		semconvai.LLMResponseModel.String(completion.Model),
 		semconvai.LLMUsageTotalTokens.Int(usage.TotalTokens),
@@ -178,12 +346,58 @@ func (llmSpan *LLMSpan) LogCompletion(ctx context.Context, completion Completion
 		semconvai.LLMUsagePromptTokens.Int(usage.PromptTokens),
 	)
 
-	setMessagesAttribute(llmSpan.span, "llm.completions", completion.Messages)
+	if completion.ID != "" {
+		llmSpan.span.SetAttributes(semconvai.LLMResponseID.String(completion.ID))
+	}
+
+	setUsageDetailsAttributes(llmSpan.span, usage)
+	if llmSpan.semconv != SemconvLegacy {
+		setFinishReasonsAttribute(llmSpan.span, completion.Messages)
+	}
+
+	llmSpan.logCompletionMessages(ctx, completion.Messages)
+	llmSpan.recordCost(ctx, completion.Model, usage)
 
 	defer llmSpan.span.End()
 	return nil
 }
 
+// setUsageDetailsAttributes records the breakdown of the token usage reported
+// by the provider, leaving out the counts it does not report.
+func setUsageDetailsAttributes(span apitrace.Span, usage Usage) {
+	details := []struct {
+		key    attribute.Key
+		tokens int
+	}{
+		{semconvai.LLMUsageCacheCreationInputTokens, usage.CacheCreationInputTokens},
+		{semconvai.LLMUsageCacheReadInputTokens, usage.CacheReadInputTokens},
+		{semconvai.LLMUsageReasoningTokens, usage.ReasoningTokens},
+		{semconvai.LLMUsagePromptAudioTokens, usage.PromptAudioTokens},
+		{semconvai.LLMUsageCompletionAudioTokens, usage.CompletionAudioTokens},
+		{semconvai.LLMUsagePromptImageTokens, usage.PromptImageTokens},
+		{semconvai.LLMUsageCompletionImageTokens, usage.CompletionImageTokens},
+	}
+	for _, detail := range details {
+		if detail.tokens > 0 {
+			span.SetAttributes(detail.key.Int(detail.tokens))
+		}
+	}
+}
+
+// setFinishReasonsAttribute records the finish reason of every choice, which
+// the GenAI conventions record on the span rather than on each completion.
+func setFinishReasonsAttribute(span apitrace.Span, messages []Message) {
+	var reasons []string
+	for _, message := range messages {
+		if message.FinishReason != "" {
+			reasons = append(reasons, message.FinishReason)
+		}
+	}
+	if len(reasons) > 0 {
+		span.SetAttributes(semconvai.GenAIResponseFinishReasons.StringSlice(reasons))
+	}
+}
+
 func (instance *Traceloop) Shutdown(ctx context.Context) {
 	if instance.tracerProvider != nil {
 		instance.tracerProvider.Shutdown(ctx)
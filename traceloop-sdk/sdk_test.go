@@ -166,4 +166,145 @@ func TestLogPromptSpanAttributes(t *testing.T) {
 	if _, exists := attributeMap["llm.completions"]; !exists {
 		t.Error("Expected llm.completions JSON attribute not found")
 	}
-}
\ No newline at end of file
+}
+
+func newTestTraceloop(t *testing.T) (*Traceloop, *tracetest.InMemoryExporter) {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := trace.NewTracerProvider(trace.WithSyncer(exporter))
+	t.Cleanup(func() { tp.Shutdown(context.Background()) })
+
+	return &Traceloop{tracerProvider: tp}, exporter
+}
+
+func exportedSpan(t *testing.T, exporter *tracetest.InMemoryExporter) (tracetest.SpanStub, map[string]interface{}) {
+	t.Helper()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+
+	return spans[0], spanAttributeMap(spans[0])
+}
+
+func spanAttributeMap(span tracetest.SpanStub) map[string]interface{} {
+	attributeMap := make(map[string]interface{})
+	for _, attr := range span.Attributes {
+		attributeMap[string(attr.Key)] = attr.Value.AsInterface()
+	}
+	return attributeMap
+}
+
+func TestLogPromptRequestParameters(t *testing.T) {
+	tl, exporter := newTestTraceloop(t)
+	ctx := context.Background()
+
+	llmSpan, err := tl.LogPrompt(ctx, Prompt{
+		Vendor:           "openai",
+		Mode:             "chat",
+		Model:            "gpt-4o-mini",
+		Temperature:      Ptr[float32](0.5),
+		TopP:             Ptr[float32](0.9),
+		Stop:             []string{"\n\n"},
+		FrequencyPenalty: Ptr[float32](0.25),
+		PresencePenalty:  Ptr[float32](0.5),
+		MaxTokens:        256,
+		Seed:             Ptr(42),
+		N:                2,
+		ResponseFormat:   "json_object",
+		ToolChoice:       "get_weather",
+		User:             "user-1",
+		LogitBias:        map[string]int{"50256": -100},
+	}, WorkflowAttributes{})
+	if err != nil {
+		t.Fatalf("LogPrompt failed: %v", err)
+	}
+	llmSpan.LogCompletion(ctx, Completion{}, Usage{})
+
+	_, attrs := exportedSpan(t, exporter)
+	expected := map[string]interface{}{
+		"llm.temperature":             0.5,
+		"llm.top_p":                   float64(float32(0.9)),
+		"llm.frequency_penalty":       0.25,
+		"llm.presence_penalty":        0.5,
+		"llm.request.max_tokens":      int64(256),
+		"llm.request.seed":            int64(42),
+		"llm.request.n":               int64(2),
+		"llm.request.response_format": "json_object",
+		"llm.request.tool_choice":     "get_weather",
+		"llm.user":                    "user-1",
+		"llm.request.logit_bias":      `{"50256":-100}`,
+	}
+	for key, value := range expected {
+		if attrs[key] != value {
+			t.Errorf("Attribute %s: expected %v, got %v", key, value, attrs[key])
+		}
+	}
+	if stop, ok := attrs["llm.chat.stop_sequences"].([]string); !ok || len(stop) != 1 || stop[0] != "\n\n" {
+		t.Errorf("Expected the stop sequences, got %v", attrs["llm.chat.stop_sequences"])
+	}
+}
+
+func TestLogPromptZeroRequestParameters(t *testing.T) {
+	tl, exporter := newTestTraceloop(t)
+	ctx := context.Background()
+
+	llmSpan, err := tl.LogPrompt(ctx, Prompt{
+		Vendor:      "openai",
+		Mode:        "chat",
+		Model:       "gpt-4o-mini",
+		Temperature: Ptr[float32](0),
+		Seed:        Ptr(0),
+	}, WorkflowAttributes{})
+	if err != nil {
+		t.Fatalf("LogPrompt failed: %v", err)
+	}
+	llmSpan.LogCompletion(ctx, Completion{}, Usage{})
+
+	_, attrs := exportedSpan(t, exporter)
+	if attrs["llm.temperature"] != 0.0 {
+		t.Errorf("Expected a temperature of 0, got %v", attrs["llm.temperature"])
+	}
+	if attrs["llm.request.seed"] != int64(0) {
+		t.Errorf("Expected a seed of 0, got %v", attrs["llm.request.seed"])
+	}
+	if _, exists := attrs["llm.top_p"]; exists {
+		t.Error("Expected no top_p when not set")
+	}
+}
+
+func TestLogCompletionUsageDetails(t *testing.T) {
+	tl, exporter := newTestTraceloop(t)
+	ctx := context.Background()
+
+	llmSpan, _ := tl.LogPrompt(ctx, Prompt{Vendor: "openai", Mode: "chat", Model: "o4-mini"}, WorkflowAttributes{})
+	llmSpan.LogCompletion(ctx, Completion{Model: "o4-mini"}, Usage{
+		PromptTokens:          1200,
+		CompletionTokens:      800,
+		TotalTokens:           2000,
+		CacheReadInputTokens:  1024,
+		ReasoningTokens:       640,
+		PromptAudioTokens:     100,
+		CompletionImageTokens: 50,
+	})
+
+	_, attrs := exportedSpan(t, exporter)
+	expected := map[string]interface{}{
+		"llm.usage.cache_read_input_tokens": int64(1024),
+		"llm.usage.reasoning_tokens":        int64(640),
+		"llm.usage.prompt_audio_tokens":     int64(100),
+		"llm.usage.completion_image_tokens": int64(50),
+	}
+	for key, value := range expected {
+		if attrs[key] != value {
+			t.Errorf("Attribute %s: expected %v, got %v", key, value, attrs[key])
+		}
+	}
+	for _, key := range []string{"llm.usage.cache_creation_input_tokens", "llm.usage.completion_audio_tokens", "llm.usage.prompt_image_tokens"} {
+		if _, exists := attrs[key]; exists {
+			t.Errorf("Expected no %s attribute when not reported", key)
+		}
+	}
+}
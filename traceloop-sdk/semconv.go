@@ -0,0 +1,124 @@
+package traceloop
+
+import (
+	semconvai "github.com/traceloop/go-openllmetry/semconv-ai"
+	"go.opentelemetry.io/otel/attribute"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+// SemconvMode selects the semantic conventions of the attributes recorded on
+// LLM spans.
+type SemconvMode int
+
+const (
+	// SemconvLegacy records the llm.* attributes of OpenLLMetry.
+	SemconvLegacy SemconvMode = iota
+	// SemconvGenAI records the gen_ai.* attributes of the OpenTelemetry GenAI
+	// semantic conventions instead of the llm.* attributes they replace.
+	// Attributes without a GenAI equivalent, such as the prompts and the
+	// completions, keep their llm.* key.
+	SemconvGenAI
+	// SemconvBoth records the gen_ai.* attributes alongside the llm.* ones.
+	SemconvBoth
+)
+
+// genAIKeys are the GenAI equivalents of the legacy attributes. Attributes
+// the GenAI conventions do not define, such as the cached and reasoning
+// tokens, keep their legacy key.
+var genAIKeys = map[attribute.Key]attribute.Key{
+	semconvai.LLMVendor:                 semconvai.GenAISystem,
+	semconvai.LLMRequestType:            semconvai.GenAIOperationName,
+	semconvai.LLMRequestModel:           semconvai.GenAIRequestModel,
+	semconvai.LLMRequestMaxTokens:       semconvai.GenAIRequestMaxTokens,
+	semconvai.LLMTemperature:            semconvai.GenAIRequestTemperature,
+	semconvai.LLMTopP:                   semconvai.GenAIRequestTopP,
+	semconvai.LLMTopK:                   semconvai.GenAIRequestTopK,
+	semconvai.LLMFrequencyPenalty:       semconvai.GenAIRequestFrequencyPenalty,
+	semconvai.LLMPresencePenalty:        semconvai.GenAIRequestPresencePenalty,
+	semconvai.LLMChatStopSequence:       semconvai.GenAIRequestStopSequences,
+	semconvai.LLMRequestSeed:            semconvai.GenAIRequestSeed,
+	semconvai.LLMRequestN:               semconvai.GenAIRequestChoiceCount,
+	semconvai.LLMRequestEmbeddingFormat: semconvai.GenAIRequestEncodingFormats,
+	semconvai.LLMResponseModel:          semconvai.GenAIResponseModel,
+	semconvai.LLMResponseID:             semconvai.GenAIResponseID,
+	semconvai.LLMUsagePromptTokens:      semconvai.GenAIUsageInputTokens,
+	semconvai.LLMUsageCompletionTokens:  semconvai.GenAIUsageOutputTokens,
+	semconvai.LLMErrorType:              semconvai.ErrorType,
+}
+
+// genAISystems are the gen_ai.system values of the vendors whose name differs
+// from their llm.vendor.
+var genAISystems = map[string]string{
+	"bedrock":   "aws.bedrock",
+	"gemini":    "gcp.gemini",
+	"vertex_ai": "gcp.vertex_ai",
+}
+
+// genAISystem returns the gen_ai.system of a vendor.
+func genAISystem(vendor string) string {
+	if system, ok := genAISystems[vendor]; ok {
+		return system
+	}
+
+	return vendor
+}
+
+// genAIOperations are the gen_ai.operation.name values of the request types
+// whose name differs from their llm.request.type.
+var genAIOperations = map[string]string{
+	"completion":  "text_completion",
+	EmbeddingMode: "embeddings",
+}
+
+// genAIAttribute returns the GenAI equivalent of a legacy attribute, and
+// false when it has none.
+func genAIAttribute(kv attribute.KeyValue) (attribute.KeyValue, bool) {
+	key, ok := genAIKeys[kv.Key]
+	if !ok {
+		return kv, false
+	}
+
+	switch kv.Key {
+	case semconvai.LLMVendor:
+		return key.String(genAISystem(kv.Value.AsString())), true
+	case semconvai.LLMRequestType:
+		if operation, ok := genAIOperations[kv.Value.AsString()]; ok {
+			return key.String(operation), true
+		}
+	case semconvai.LLMRequestEmbeddingFormat:
+		return key.StringSlice([]string{kv.Value.AsString()}), true
+	}
+
+	return attribute.KeyValue{Key: key, Value: kv.Value}, true
+}
+
+// convertAttributes returns the attributes to record in mode.
+func (mode SemconvMode) convertAttributes(attrs []attribute.KeyValue) []attribute.KeyValue {
+	if mode == SemconvLegacy {
+		return attrs
+	}
+
+	converted := make([]attribute.KeyValue, 0, len(attrs))
+	for _, kv := range attrs {
+		genAI, ok := genAIAttribute(kv)
+		if !ok || mode == SemconvBoth {
+			converted = append(converted, kv)
+		}
+		if ok {
+			converted = append(converted, genAI)
+		}
+	}
+
+	return converted
+}
+
+// semconvSpan is an LLM span recording the attributes set on it in the
+// conventions of mode.
+type semconvSpan struct {
+	apitrace.Span
+	mode SemconvMode
+}
+
+func (s semconvSpan) SetAttributes(kv ...attribute.KeyValue) {
+	s.Span.SetAttributes(s.mode.convertAttributes(kv)...)
+}
@@ -0,0 +1,100 @@
+package traceloop
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func logTestCompletion(t *testing.T, mode SemconvMode) map[string]interface{} {
+	tl, exporter := newTestTraceloop(t)
+	tl.config.Semconv = mode
+
+	ctx := context.Background()
+	llmSpan, _ := tl.LogPrompt(ctx, Prompt{
+		Vendor:      "gemini",
+		Mode:        "completion",
+		Model:       "gemini-2.5-flash",
+		Temperature: Ptr[float32](0.5),
+		N:           2,
+		Messages:    []Message{{Index: 0, Role: "user", Content: "Hello"}},
+	}, WorkflowAttributes{Name: "greeting"})
+	llmSpan.LogCompletion(ctx, Completion{
+		ID:    "resp-1",
+		Model: "gemini-2.5-flash-001",
+		Messages: []Message{
+			{Index: 0, Role: "assistant", Content: "Hi", FinishReason: "stop"},
+			{Index: 1, Role: "assistant", Content: "Hello", FinishReason: "length"},
+		},
+	}, Usage{PromptTokens: 3, CompletionTokens: 4, TotalTokens: 7, CacheReadInputTokens: 2, ReasoningTokens: 1})
+
+	_, attrs := exportedSpan(t, exporter)
+	return attrs
+}
+
+func TestSemconvGenAI(t *testing.T) {
+	attrs := logTestCompletion(t, SemconvGenAI)
+
+	expected := map[string]interface{}{
+		"gen_ai.system":                     "gcp.gemini",
+		"gen_ai.operation.name":             "text_completion",
+		"gen_ai.request.model":              "gemini-2.5-flash",
+		"gen_ai.request.temperature":        0.5,
+		"gen_ai.request.choice.count":       int64(2),
+		"gen_ai.response.id":                "resp-1",
+		"gen_ai.response.model":             "gemini-2.5-flash-001",
+		"gen_ai.usage.input_tokens":         int64(3),
+		"gen_ai.usage.output_tokens":        int64(4),
+		"llm.usage.cache_read_input_tokens": int64(2),
+		"llm.usage.reasoning_tokens":        int64(1),
+		"llm.usage.total_tokens":            int64(7),
+		"llm.prompts.0.content":             "Hello",
+		"traceloop.workflow.name":           "greeting",
+	}
+	for key, value := range expected {
+		if attrs[key] != value {
+			t.Errorf("Attribute %s: expected %v, got %v", key, value, attrs[key])
+		}
+	}
+	if reasons := attrs["gen_ai.response.finish_reasons"]; !reflect.DeepEqual(reasons, []string{"stop", "length"}) {
+		t.Errorf("Expected the finish reasons of both choices, got %v", reasons)
+	}
+
+	for _, key := range []string{"llm.vendor", "llm.request.model", "llm.usage.prompt_tokens", "gen_ai.usage.cache_read_input_tokens", "gen_ai.usage.reasoning_tokens"} {
+		if _, exists := attrs[key]; exists {
+			t.Errorf("Expected no attribute %s", key)
+		}
+	}
+}
+
+func TestSemconvBoth(t *testing.T) {
+	attrs := logTestCompletion(t, SemconvBoth)
+
+	expected := map[string]interface{}{
+		"llm.vendor":                "gemini",
+		"gen_ai.system":             "gcp.gemini",
+		"llm.request.type":          "completion",
+		"gen_ai.operation.name":     "text_completion",
+		"llm.usage.prompt_tokens":   int64(3),
+		"gen_ai.usage.input_tokens": int64(3),
+	}
+	for key, value := range expected {
+		if attrs[key] != value {
+			t.Errorf("Attribute %s: expected %v, got %v", key, value, attrs[key])
+		}
+	}
+}
+
+func TestSemconvLegacy(t *testing.T) {
+	attrs := logTestCompletion(t, SemconvLegacy)
+
+	if attrs["llm.vendor"] != "gemini" {
+		t.Errorf("Expected the legacy vendor, got %v", attrs["llm.vendor"])
+	}
+	for key := range attrs {
+		if strings.HasPrefix(key, "gen_ai.") {
+			t.Errorf("Expected no GenAI attribute, got %s", key)
+		}
+	}
+}
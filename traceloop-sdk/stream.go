@@ -0,0 +1,223 @@
+package traceloop
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	semconvai "github.com/traceloop/go-openllmetry/semconv-ai"
+	"go.opentelemetry.io/otel/attribute"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+// StreamChunkEvent is the name of the span event recorded for every chunk
+// of a streamed completion.
+const StreamChunkEvent = "llm.content.completion.chunk"
+
+// StreamChunk is a delta of a streamed completion. Content and tool call
+// arguments are appended to the ones received before for the same choice,
+// while the other fields replace them when set.
+type StreamChunk struct {
+	// ID is the identifier of the response, which vendors repeat on every
+	// chunk or send with the first one.
+	ID           string
+	Model        string
+	Index        int
+	Role         string
+	Content      string
+	FinishReason string
+	ToolCalls    []ToolCallDelta
+	// Usage is set on the chunk reporting the token counts, usually the
+	// last one.
+	Usage *Usage
+}
+
+// ToolCallDelta is a fragment of a streamed tool call. Index identifies the
+// tool call within its choice, as vendors only send the ID and the name with
+// the first fragment.
+type ToolCallDelta struct {
+	Index     int
+	ID        string
+	Type      string
+	Name      string
+	Arguments string
+}
+
+// CompletionStream assembles a streamed completion from its chunks and logs
+// it on the LLM span once the stream is closed or its context is cancelled.
+type CompletionStream struct {
+	llmSpan    LLMSpan
+	ctx        context.Context
+	stop       func() bool
+	mutex      sync.Mutex
+	id         string
+	model      string
+	choices    map[int]*streamChoice
+	usage      Usage
+	firstChunk time.Time
+	done       bool
+}
+
+type streamChoice struct {
+	role         string
+	content      strings.Builder
+	finishReason string
+	toolCalls    []ToolCall
+}
+
+// NewStream starts accumulating a streamed completion. The time to first
+// token is measured from the call to LogPrompt. If ctx is cancelled before
+// Close is called, the stream is closed with the context's error.
+func (llmSpan *LLMSpan) NewStream(ctx context.Context) *CompletionStream {
+	llmSpan.span.SetAttributes(semconvai.LLMIsStreaming.Bool(true))
+
+	stream := &CompletionStream{
+		llmSpan: *llmSpan,
+		ctx:     ctx,
+		choices: make(map[int]*streamChoice),
+	}
+	stream.stop = context.AfterFunc(ctx, func() {
+		stream.finish(ctx.Err())
+	})
+
+	return stream
+}
+
+// AddChunk records a chunk as a span event and accumulates it into the
+// completion. Chunks added after the stream is closed are ignored.
+func (stream *CompletionStream) AddChunk(chunk StreamChunk) {
+	stream.mutex.Lock()
+	defer stream.mutex.Unlock()
+
+	if stream.done {
+		return
+	}
+
+	span := stream.llmSpan.span
+	if stream.firstChunk.IsZero() {
+		stream.firstChunk = time.Now()
+		span.SetAttributes(semconvai.LLMResponseTimeToFirstToken.Float64(stream.firstChunk.Sub(stream.llmSpan.startTime).Seconds()))
+	}
+
+	eventAttrs := []attribute.KeyValue{attribute.Int("index", chunk.Index)}
+	if chunk.Content != "" {
+		eventAttrs = append(eventAttrs, attribute.String("content", chunk.Content))
+	}
+	if chunk.FinishReason != "" {
+		eventAttrs = append(eventAttrs, attribute.String("finish_reason", chunk.FinishReason))
+	}
+	span.AddEvent(StreamChunkEvent, apitrace.WithAttributes(eventAttrs...))
+
+	if chunk.ID != "" {
+		stream.id = chunk.ID
+	}
+	if chunk.Model != "" {
+		stream.model = chunk.Model
+	}
+	if chunk.Usage != nil {
+		stream.usage = *chunk.Usage
+	}
+
+	choice, ok := stream.choices[chunk.Index]
+	if !ok {
+		choice = &streamChoice{}
+		stream.choices[chunk.Index] = choice
+	}
+
+	if chunk.Role != "" {
+		choice.role = chunk.Role
+	}
+	choice.content.WriteString(chunk.Content)
+	if chunk.FinishReason != "" {
+		choice.finishReason = chunk.FinishReason
+	}
+
+	for _, delta := range chunk.ToolCalls {
+		for len(choice.toolCalls) <= delta.Index {
+			choice.toolCalls = append(choice.toolCalls, ToolCall{})
+		}
+
+		toolCall := &choice.toolCalls[delta.Index]
+		if delta.ID != "" {
+			toolCall.ID = delta.ID
+		}
+		if delta.Type != "" {
+			toolCall.Type = delta.Type
+		}
+		if delta.Name != "" {
+			toolCall.Function.Name = delta.Name
+		}
+		toolCall.Function.Arguments += delta.Arguments
+	}
+}
+
+// Close logs the assembled completion and ends the LLM span. It is safe to
+// call more than once. If the stream's context is already cancelled, the
+// span records the context's error.
+func (stream *CompletionStream) Close() {
+	stream.finish(stream.ctx.Err())
+}
+
+// CloseWithError logs the completion assembled so far and ends the LLM span
+// with the error that interrupted the stream, as LogError does.
+func (stream *CompletionStream) CloseWithError(err error, opts ...ErrorOption) {
+	stream.finish(err, opts...)
+}
+
+func (stream *CompletionStream) finish(err error, opts ...ErrorOption) {
+	stream.mutex.Lock()
+	defer stream.mutex.Unlock()
+
+	if stream.done {
+		return
+	}
+	stream.done = true
+	stream.stop()
+
+	span := stream.llmSpan.span
+	if err != nil {
+		recordError(span, err, opts...)
+	}
+
+	if !stream.firstChunk.IsZero() && stream.usage.CompletionTokens > 0 {
+		if elapsed := time.Since(stream.firstChunk).Seconds(); elapsed > 0 {
+			span.SetAttributes(semconvai.LLMResponseTokensPerSecond.Float64(float64(stream.usage.CompletionTokens) / elapsed))
+		}
+	}
+
+	indexes := make([]int, 0, len(stream.choices))
+	for index := range stream.choices {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	var messages []Message
+	for _, index := range indexes {
+		choice := stream.choices[index]
+		role := choice.role
+		if role == "" {
+			role = "assistant"
+		}
+		messages = append(messages, Message{
+			Index:        index,
+			Role:         role,
+			Content:      choice.content.String(),
+			ToolCalls:    choice.toolCalls,
+			FinishReason: choice.finishReason,
+		})
+	}
+
+	// Not every vendor names the model serving the request in its chunks.
+	model := stream.model
+	if model == "" {
+		model = stream.llmSpan.model
+	}
+
+	stream.llmSpan.LogCompletion(stream.ctx, Completion{
+		ID:       stream.id,
+		Model:    model,
+		Messages: messages,
+	}, stream.usage)
+}
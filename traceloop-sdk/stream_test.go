@@ -0,0 +1,90 @@
+package traceloop
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestCompletionStream(t *testing.T) {
+	tl, exporter := newTestTraceloop(t)
+	ctx := context.Background()
+
+	llmSpan, err := tl.LogPrompt(ctx, Prompt{
+		Vendor:   "openai",
+		Mode:     "chat",
+		Model:    "gpt-4o-mini",
+		Messages: []Message{{Index: 0, Role: "user", Content: "Weather in Paris?"}},
+	}, WorkflowAttributes{})
+	if err != nil {
+		t.Fatalf("LogPrompt failed: %v", err)
+	}
+
+	stream := llmSpan.NewStream(ctx)
+	stream.AddChunk(StreamChunk{ID: "chatcmpl-1", Model: "gpt-4o-mini-2024-07-18", Role: "assistant", Content: "Let me check."})
+	stream.AddChunk(StreamChunk{ToolCalls: []ToolCallDelta{{Index: 0, ID: "call_1", Type: "function", Name: "get_weather", Arguments: `{"city"`}}})
+	stream.AddChunk(StreamChunk{ToolCalls: []ToolCallDelta{{Index: 0, Arguments: `:"Paris"}`}}})
+	stream.AddChunk(StreamChunk{FinishReason: "tool_calls", Usage: &Usage{PromptTokens: 10, CompletionTokens: 8, TotalTokens: 18}})
+	stream.Close()
+	stream.Close()
+	stream.AddChunk(StreamChunk{Content: "ignored"})
+
+	span, attrs := exportedSpan(t, exporter)
+	expected := map[string]interface{}{
+		"llm.is_streaming":                         true,
+		"llm.response.id":                          "chatcmpl-1",
+		"llm.response.model":                       "gpt-4o-mini-2024-07-18",
+		"llm.completions.0.role":                   "assistant",
+		"llm.completions.0.content":                "Let me check.",
+		"llm.completions.0.finish_reason":          "tool_calls",
+		"llm.completions.0.tool_calls.0.id":        "call_1",
+		"llm.completions.0.tool_calls.0.name":      "get_weather",
+		"llm.completions.0.tool_calls.0.arguments": `{"city":"Paris"}`,
+		"llm.usage.completion_tokens":              int64(8),
+	}
+	for key, value := range expected {
+		if attrs[key] != value {
+			t.Errorf("Attribute %s: expected %v, got %v", key, value, attrs[key])
+		}
+	}
+
+	if ttft, ok := attrs["llm.response.time_to_first_token"].(float64); !ok || ttft < 0 {
+		t.Errorf("Expected a time to first token, got %v", attrs["llm.response.time_to_first_token"])
+	}
+	if _, ok := attrs["llm.response.tokens_per_second"].(float64); !ok {
+		t.Errorf("Expected tokens per second, got %v", attrs["llm.response.tokens_per_second"])
+	}
+	if len(span.Events) != 4 || span.Events[0].Name != StreamChunkEvent {
+		t.Errorf("Expected an event per chunk, got %v", span.Events)
+	}
+}
+
+func TestCompletionStreamCancelled(t *testing.T) {
+	tl, exporter := newTestTraceloop(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	llmSpan, err := tl.LogPrompt(ctx, Prompt{Vendor: "openai", Mode: "chat", Model: "gpt-4o-mini"}, WorkflowAttributes{})
+	if err != nil {
+		t.Fatalf("LogPrompt failed: %v", err)
+	}
+
+	stream := llmSpan.NewStream(ctx)
+	stream.AddChunk(StreamChunk{Content: "Partial"})
+	cancel()
+
+	// Closing the stream right after the cancellation must not race with
+	// its finalization.
+	stream.Close()
+
+	span, attrs := exportedSpan(t, exporter)
+	if attrs["llm.completions.0.content"] != "Partial" {
+		t.Errorf("Expected the partial completion to be logged, got %v", attrs["llm.completions.0.content"])
+	}
+	if attrs["llm.response.model"] != "gpt-4o-mini" {
+		t.Errorf("Expected the response model to default to the requested one, got %v", attrs["llm.response.model"])
+	}
+	if span.Status.Code != codes.Error || span.Status.Description != context.Canceled.Error() {
+		t.Errorf("Expected the span to record the cancellation, got %v", span.Status)
+	}
+}
@@ -0,0 +1,233 @@
+package traceloop
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/pkoukk/tiktoken-go"
+	semconvai "github.com/traceloop/go-openllmetry/semconv-ai"
+)
+
+// Tokenizer counts the tokens of texts as a family of models does.
+type Tokenizer interface {
+	CountTokens(text string) (int, error)
+}
+
+// TokenizerFunc adapts a function to the Tokenizer interface.
+type TokenizerFunc func(text string) (int, error)
+
+func (f TokenizerFunc) CountTokens(text string) (int, error) {
+	return f(text)
+}
+
+// TiktokenTokenizer loads the named tiktoken encoding, e.g. o200k_base or
+// cl100k_base, and returns a tokenizer using it. The encoding is downloaded
+// unless a loader is set with tiktoken.SetBpeLoader.
+func TiktokenTokenizer(encoding string) (Tokenizer, error) {
+	tke, err := tiktoken.GetEncoding(encoding)
+	if err != nil {
+		return nil, fmt.Errorf("load %s encoding: %w", encoding, err)
+	}
+
+	return TokenizerFunc(func(text string) (int, error) {
+		return len(tke.EncodeOrdinary(text)), nil
+	}), nil
+}
+
+// defaultEncodings are the tiktoken encodings of OpenAI models, by model name
+// prefix.
+var defaultEncodings = map[string]string{
+	"gpt-5":                  "o200k_base",
+	"gpt-4.1":                "o200k_base",
+	"gpt-4.5":                "o200k_base",
+	"gpt-4o":                 "o200k_base",
+	"chatgpt-4o":             "o200k_base",
+	"o1":                     "o200k_base",
+	"o3":                     "o200k_base",
+	"o4":                     "o200k_base",
+	"gpt-4":                  "cl100k_base",
+	"gpt-3.5-turbo":          "cl100k_base",
+	"text-embedding-3":       "cl100k_base",
+	"text-embedding-ada-002": "cl100k_base",
+}
+
+// newTokenizers returns the tokenizers of OpenAI models, overridden and
+// extended by those of the config, or nil unless token counting is enabled.
+// Each encoding is loaded once, the first time a model using it is estimated.
+func newTokenizers(config TokenCountingConfig) map[string]Tokenizer {
+	if !config.Enabled {
+		return nil
+	}
+
+	tokenizers := make(map[string]Tokenizer, len(defaultEncodings)+len(config.Tokenizers))
+	encodings := make(map[string]*lazyEncoding)
+	for prefix, encoding := range defaultEncodings {
+		if _, overridden := config.Tokenizers[prefix]; overridden {
+			continue
+		}
+
+		if _, created := encodings[encoding]; !created {
+			encodings[encoding] = newLazyEncoding(encoding)
+		}
+		tokenizers[prefix] = encodings[encoding]
+	}
+	for prefix, tokenizer := range config.Tokenizers {
+		tokenizers[prefix] = tokenizer
+	}
+
+	return tokenizers
+}
+
+// lazyEncoding is the tokenizer of a tiktoken encoding, loaded in the
+// background the first time it counts tokens, so that neither NewClient nor
+// logging a completion waits for the download. Until the encoding is loaded,
+// or when it failed to load, counting fails and the usage is not estimated.
+type lazyEncoding struct {
+	name      string
+	once      sync.Once
+	loaded    chan struct{}
+	tokenizer Tokenizer
+	err       error
+}
+
+func newLazyEncoding(name string) *lazyEncoding {
+	return &lazyEncoding{name: name, loaded: make(chan struct{})}
+}
+
+func (e *lazyEncoding) CountTokens(text string) (int, error) {
+	e.once.Do(func() { go e.load() })
+
+	select {
+	case <-e.loaded:
+	default:
+		return 0, fmt.Errorf("%s encoding is still loading", e.name)
+	}
+	if e.err != nil {
+		return 0, e.err
+	}
+
+	return e.tokenizer.CountTokens(text)
+}
+
+func (e *lazyEncoding) load() {
+	defer close(e.loaded)
+
+	if e.tokenizer, e.err = TiktokenTokenizer(e.name); e.err != nil {
+		fmt.Printf("Failed to load tokenizer, not estimating usage: %v\n", e.err)
+	}
+}
+
+// tokenizer returns the tokenizer of model, or nil when there is none.
+func (instance *Traceloop) tokenizer(model string) Tokenizer {
+	if model == "" || instance.tokenizers == nil {
+		return nil
+	}
+	if tokenizer, ok := lookupModel(instance.tokenizers, model); ok {
+		return tokenizer
+	}
+
+	tokenizer, _ := lookupModel(instance.tokenizers, trimModelPrefix(model))
+	return tokenizer
+}
+
+// Chat models wrap each message, and the reply they are primed for, in a few
+// tokens of their own.
+const (
+	tokensPerMessage = 3
+	tokensPerReply   = 3
+)
+
+// estimateUsage counts the tokens of the prompt and of the completion with
+// the span's tokenizer. It reports false when the span has no tokenizer or
+// the texts could not be tokenized.
+func (llmSpan *LLMSpan) estimateUsage(completion Completion) (Usage, bool) {
+	if llmSpan.tokenizer == nil || llmSpan.prompt == nil {
+		return Usage{}, false
+	}
+
+	chat := llmSpan.prompt.Mode == "chat"
+	prompt := tokenCounter{tokenizer: llmSpan.tokenizer}
+	for _, message := range llmSpan.prompt.Messages {
+		prompt.addMessage(message, chat)
+	}
+	for _, tool := range llmSpan.prompt.Tools {
+		prompt.add(tool.Function.Name)
+		prompt.add(tool.Function.Description)
+		if tool.Function.Parameters != nil {
+			parameters, _ := json.Marshal(tool.Function.Parameters)
+			prompt.add(string(parameters))
+		}
+	}
+	if chat {
+		prompt.tokens += tokensPerReply
+	}
+
+	completionTokens := tokenCounter{tokenizer: llmSpan.tokenizer}
+	for _, message := range completion.Messages {
+		completionTokens.addMessage(message, false)
+	}
+
+	if prompt.err != nil || completionTokens.err != nil {
+		return Usage{}, false
+	}
+
+	return Usage{
+		PromptTokens:     prompt.tokens,
+		CompletionTokens: completionTokens.tokens,
+		TotalTokens:      prompt.tokens + completionTokens.tokens,
+	}, true
+}
+
+// logEstimatedUsage replaces a usage the provider did not report by an
+// estimate, marked as such on the span.
+func (llmSpan *LLMSpan) logEstimatedUsage(completion Completion, usage Usage) Usage {
+	if usage.PromptTokens != 0 || usage.CompletionTokens != 0 || usage.TotalTokens != 0 {
+		return usage
+	}
+
+	estimated, ok := llmSpan.estimateUsage(completion)
+	if !ok {
+		return usage
+	}
+	llmSpan.span.SetAttributes(semconvai.LLMUsageEstimated.Bool(true))
+
+	return estimated
+}
+
+type tokenCounter struct {
+	tokenizer Tokenizer
+	tokens    int
+	err       error
+}
+
+func (c *tokenCounter) add(text string) {
+	if c.err != nil || text == "" {
+		return
+	}
+
+	tokens, err := c.tokenizer.CountTokens(text)
+	if err != nil {
+		c.err = err
+		return
+	}
+	c.tokens += tokens
+}
+
+func (c *tokenCounter) addMessage(message Message, chat bool) {
+	if chat {
+		c.tokens += tokensPerMessage
+		c.add(message.Role)
+	}
+
+	content := message.Content
+	if content == "" {
+		content = partsText(message.ContentParts)
+	}
+	c.add(content)
+
+	for _, toolCall := range message.ToolCalls {
+		c.add(toolCall.Function.Name)
+		c.add(toolCall.Function.Arguments)
+	}
+}
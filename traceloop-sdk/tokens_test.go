@@ -0,0 +1,157 @@
+package traceloop
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pkoukk/tiktoken-go"
+)
+
+// countWords is a tokenizer counting a token per word.
+var countWords = TokenizerFunc(func(text string) (int, error) {
+	return len(strings.Fields(text)), nil
+})
+
+func TestEstimatedUsage(t *testing.T) {
+	tl, exporter := newTestTraceloop(t)
+	useTestBpeLoader(t)
+	tl.tokenizers = newTokenizers(TokenCountingConfig{
+		Enabled:    true,
+		Tokenizers: map[string]Tokenizer{"test-model": countWords},
+	})
+
+	ctx := context.Background()
+	llmSpan, _ := tl.LogPrompt(ctx, Prompt{
+		Vendor: "test",
+		Mode:   "chat",
+		Model:  "test-model",
+		Messages: []Message{
+			{Index: 0, Role: "system", Content: "You are helpful"},
+			{Index: 1, Role: "user", Content: "What is the weather"},
+		},
+		Tools: []Tool{{
+			Type: "function",
+			Function: ToolFunction{
+				Name:        "get_weather",
+				Description: "Get the weather",
+				Parameters:  map[string]interface{}{"type": "object"},
+			},
+		}},
+	}, WorkflowAttributes{})
+	llmSpan.LogCompletion(ctx, Completion{
+		Model:    "test-model",
+		Messages: []Message{{Index: 0, Role: "assistant", Content: "It is sunny"}},
+	}, Usage{})
+
+	_, attrs := exportedSpan(t, exporter)
+	// Two messages of 3 + 1 tokens plus their content, 5 tokens of tool
+	// definition, and 3 tokens priming the reply.
+	expected := map[string]interface{}{
+		"llm.usage.prompt_tokens":     int64(23),
+		"llm.usage.completion_tokens": int64(3),
+		"llm.usage.total_tokens":      int64(26),
+		"llm.usage.estimated":         true,
+	}
+	for key, value := range expected {
+		if attrs[key] != value {
+			t.Errorf("Attribute %s: expected %v, got %v", key, value, attrs[key])
+		}
+	}
+}
+
+func TestReportedUsageNotEstimated(t *testing.T) {
+	tl, exporter := newTestTraceloop(t)
+	useTestBpeLoader(t)
+	tl.tokenizers = newTokenizers(TokenCountingConfig{
+		Enabled:    true,
+		Tokenizers: map[string]Tokenizer{"test-model": countWords},
+	})
+
+	ctx := context.Background()
+	llmSpan, _ := tl.LogPrompt(ctx, Prompt{
+		Vendor:   "test",
+		Mode:     "chat",
+		Model:    "test-model",
+		Messages: []Message{{Index: 0, Role: "user", Content: "Hello there"}},
+	}, WorkflowAttributes{})
+	llmSpan.LogCompletion(ctx, Completion{Model: "test-model"}, Usage{PromptTokens: 12, TotalTokens: 12})
+
+	_, attrs := exportedSpan(t, exporter)
+	if attrs["llm.usage.prompt_tokens"] != int64(12) {
+		t.Errorf("Expected the reported prompt tokens, got %v", attrs["llm.usage.prompt_tokens"])
+	}
+	if _, exists := attrs["llm.usage.estimated"]; exists {
+		t.Error("Expected the reported usage not to be marked as estimated")
+	}
+}
+
+type testBpeLoader map[string]int
+
+func (l testBpeLoader) LoadTiktokenBpe(string) (map[string]int, error) {
+	return l, nil
+}
+
+// useTestBpeLoader loads every encoding offline: every byte is a token, and
+// "he" is merged into one.
+func useTestBpeLoader(t *testing.T) {
+	ranks := testBpeLoader{"he": 256}
+	for b := 0; b < 256; b++ {
+		ranks[string([]byte{byte(b)})] = b
+	}
+	tiktoken.SetBpeLoader(ranks)
+	t.Cleanup(func() { tiktoken.SetBpeLoader(tiktoken.NewDefaultBpeLoader()) })
+}
+
+func TestTiktokenTokenizer(t *testing.T) {
+	useTestBpeLoader(t)
+
+	tokenizer, err := TiktokenTokenizer("cl100k_base")
+	if err != nil {
+		t.Fatalf("TiktokenTokenizer failed: %v", err)
+	}
+	tokens, err := tokenizer.CountTokens("hello world")
+	if err != nil {
+		t.Fatalf("CountTokens failed: %v", err)
+	}
+	if tokens != 10 {
+		t.Errorf("Expected 10 tokens, got %d", tokens)
+	}
+}
+
+func TestLazyEncoding(t *testing.T) {
+	useTestBpeLoader(t)
+
+	encoding := newLazyEncoding("cl100k_base")
+	if _, err := encoding.CountTokens("hello world"); err == nil {
+		t.Error("Expected counting to fail while the encoding is loading")
+	}
+
+	<-encoding.loaded
+	tokens, err := encoding.CountTokens("hello world")
+	if err != nil {
+		t.Fatalf("CountTokens failed: %v", err)
+	}
+	if tokens != 10 {
+		t.Errorf("Expected 10 tokens, got %d", tokens)
+	}
+}
+
+func TestDefaultTokenizers(t *testing.T) {
+	useTestBpeLoader(t)
+	tl := &Traceloop{tokenizers: newTokenizers(TokenCountingConfig{Enabled: true})}
+
+	for _, model := range []string{"gpt-4o-mini-2024-07-18", "gpt-4.1", "gpt-4-turbo", "gpt-3.5-turbo-0125"} {
+		if tl.tokenizer(model) == nil {
+			t.Errorf("Expected a tokenizer for %s", model)
+		}
+	}
+	if tl.tokenizer("claude-sonnet-4") != nil {
+		t.Error("Expected no tokenizer for claude-sonnet-4")
+	}
+
+	disabled := &Traceloop{tokenizers: newTokenizers(TokenCountingConfig{})}
+	if disabled.tokenizer("gpt-4o") != nil {
+		t.Error("Expected no tokenizer unless token counting is enabled")
+	}
+}
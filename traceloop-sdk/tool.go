@@ -0,0 +1,64 @@
+package traceloop
+
+import (
+	"context"
+
+	semconvai "github.com/traceloop/go-openllmetry/semconv-ai"
+	"go.opentelemetry.io/otel/codes"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+// ToolSpan traces the execution of a tool requested by a model. It records
+// the tool call's ID, so the span can be matched with the tool call on the
+// completion and with the tool result message sent back to the model.
+type ToolSpan struct {
+	span apitrace.Span
+	ctx  context.Context
+}
+
+func (instance *Traceloop) newTool(ctx context.Context, workflowName string, toolCall ToolCall) *ToolSpan {
+	tCtx, span := instance.startEntity(ctx, workflowName, "tool", toolCall.Function.Name)
+
+	span.SetAttributes(semconvai.TraceloopEntityInput.String(toolCall.Function.Arguments))
+	if toolCall.ID != "" {
+		span.SetAttributes(semconvai.TraceloopToolCallID.String(toolCall.ID))
+	}
+
+	return &ToolSpan{
+		span: span,
+		ctx:  tCtx,
+	}
+}
+
+// NewTool starts the span of a tool executed as part of the workflow.
+func (workflow *Workflow) NewTool(toolCall ToolCall) *ToolSpan {
+	return workflow.sdk.newTool(workflow.ctx, workflow.Attributes.Name, toolCall)
+}
+
+// NewTool starts the span of a tool executed as part of the task.
+func (task *Task) NewTool(toolCall ToolCall) *ToolSpan {
+	return task.workflow.sdk.newTool(task.ctx, task.workflow.Attributes.Name, toolCall)
+}
+
+// Context returns the context carrying the tool span, so calls made by the
+// tool are recorded under it.
+func (toolSpan *ToolSpan) Context() context.Context {
+	return toolSpan.ctx
+}
+
+// LogResult records the result returned to the model and ends the span.
+func (toolSpan *ToolSpan) LogResult(ctx context.Context, result string) error {
+	toolSpan.span.SetAttributes(semconvai.TraceloopEntityOutput.String(result))
+
+	defer toolSpan.span.End()
+	return nil
+}
+
+// LogError records the failure of the tool and ends the span.
+func (toolSpan *ToolSpan) LogError(ctx context.Context, err error) error {
+	toolSpan.span.RecordError(err)
+	toolSpan.span.SetStatus(codes.Error, err.Error())
+
+	defer toolSpan.span.End()
+	return nil
+}
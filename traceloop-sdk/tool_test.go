@@ -0,0 +1,91 @@
+package traceloop
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestToolSpan(t *testing.T) {
+	tl, exporter := newTestTraceloop(t)
+	ctx := context.Background()
+
+	wf := tl.NewWorkflow(ctx, WorkflowAttributes{Name: "weather"})
+	task := wf.NewTask("answer")
+	tool := task.NewTool(ToolCall{
+		ID:       "call_1",
+		Type:     "function",
+		Function: ToolCallFunction{Name: "get_weather", Arguments: `{"location":"Paris"}`},
+	})
+	tool.LogResult(tool.Context(), "Sunny, 22C")
+
+	llmSpan, _ := task.LogPrompt(Prompt{
+		Vendor: "openai",
+		Mode:   "chat",
+		Messages: []Message{
+			{Index: 0, Role: "tool", Content: "Sunny, 22C", ToolCallID: "call_1"},
+		},
+	})
+	llmSpan.LogCompletion(ctx, Completion{}, Usage{})
+	task.End()
+	wf.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 4 {
+		t.Fatalf("Expected 4 spans, got %d", len(spans))
+	}
+	toolSpan := spans[0]
+	if toolSpan.Name != "get_weather.tool" {
+		t.Errorf("Expected span name get_weather.tool, got %s", toolSpan.Name)
+	}
+	if toolSpan.Parent.SpanID() != spans[2].SpanContext.SpanID() {
+		t.Errorf("Expected the tool span to be a child of the task span")
+	}
+
+	attrs := spanAttributeMap(toolSpan)
+	expected := map[string]interface{}{
+		"traceloop.span.kind":     "tool",
+		"traceloop.workflow.name": "weather",
+		"traceloop.entity.name":   "get_weather",
+		"traceloop.entity.input":  `{"location":"Paris"}`,
+		"traceloop.entity.output": "Sunny, 22C",
+		"traceloop.tool.call_id":  "call_1",
+	}
+	for key, value := range expected {
+		if attrs[key] != value {
+			t.Errorf("Attribute %s: expected %v, got %v", key, value, attrs[key])
+		}
+	}
+
+	toolCallID := ""
+	for _, attr := range spans[1].Attributes {
+		if attr.Key == "llm.prompts.0.tool_call_id" {
+			toolCallID = attr.Value.AsString()
+		}
+	}
+	if toolCallID != "call_1" {
+		t.Errorf("Expected the tool message to reference call_1, got %q", toolCallID)
+	}
+}
+
+func TestToolSpanError(t *testing.T) {
+	tl, exporter := newTestTraceloop(t)
+	ctx := context.Background()
+
+	wf := tl.NewWorkflow(ctx, WorkflowAttributes{Name: "weather"})
+	tool := wf.NewTool(ToolCall{ID: "call_1", Function: ToolCallFunction{Name: "get_weather"}})
+	tool.LogError(ctx, errors.New("weather service unavailable"))
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	if status := spans[0].Status; status.Code != codes.Error || status.Description != "weather service unavailable" {
+		t.Errorf("Expected the span status to be an error, got %v", status)
+	}
+	if len(spans[0].Events) != 1 || spans[0].Events[0].Name != "exception" {
+		t.Errorf("Expected an exception event, got %v", spans[0].Events)
+	}
+}
@@ -0,0 +1,75 @@
+// Package tracelooptest provides the fixtures shared by the tests of the
+// instrumentations: a Traceloop client exporting to memory, and helpers to
+// check the attributes of the spans it exported.
+package tracelooptest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	sdk "github.com/traceloop/go-openllmetry/traceloop-sdk"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// NewServer starts a test server answering both the instrumented SDK and the
+// Traceloop client with handler, and returns the client, whose spans are
+// exported synchronously to the returned exporter. The server is closed when
+// the test ends.
+func NewServer(t testing.TB, handler http.HandlerFunc) (*httptest.Server, *sdk.Traceloop, *tracetest.InMemoryExporter) {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	exporter := tracetest.NewInMemoryExporter()
+	tl, err := sdk.NewClient(context.Background(), sdk.Config{
+		BaseURL:      server.URL,
+		Exporter:     exporter,
+		DisableBatch: true,
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	return server, tl, exporter
+}
+
+// SpanAttributes returns the attributes of the only span exported, failing
+// the test if there is not exactly one.
+func SpanAttributes(t testing.TB, exporter *tracetest.InMemoryExporter) map[string]interface{} {
+	t.Helper()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+
+	return Attributes(spans[0])
+}
+
+// Attributes returns the attributes of a span by key.
+func Attributes(span tracetest.SpanStub) map[string]interface{} {
+	attributeMap := make(map[string]interface{})
+	for _, attr := range span.Attributes {
+		attributeMap[string(attr.Key)] = attr.Value.AsInterface()
+	}
+
+	return attributeMap
+}
+
+// AssertAttributes checks that attributeMap holds every expected attribute
+// with its expected value.
+func AssertAttributes(t testing.TB, attributeMap map[string]interface{}, expectedAttrs map[string]interface{}) {
+	t.Helper()
+
+	for expectedKey, expectedValue := range expectedAttrs {
+		actualValue, exists := attributeMap[expectedKey]
+		if !exists {
+			t.Errorf("Expected attribute %s not found", expectedKey)
+		} else if actualValue != expectedValue {
+			t.Errorf("Attribute %s: expected %v, got %v", expectedKey, expectedValue, actualValue)
+		}
+	}
+}
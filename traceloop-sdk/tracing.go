@@ -100,7 +100,7 @@ func resourceName(serviceName string) string {
 	return "unknown_service"
 }
 
-func newTracerProvider(ctx context.Context, serviceName string, exp trace.SpanExporter) (*trace.TracerProvider, error) {
+func newTracerProvider(ctx context.Context, serviceName string, exp trace.SpanExporter, disableBatch bool) (*trace.TracerProvider, error) {
 	r, err := resource.New(
 		ctx,
 		resource.WithAttributes(
@@ -112,19 +112,30 @@ func newTracerProvider(ctx context.Context, serviceName string, exp trace.SpanEx
 		return nil, err
 	}
 
+	var processor trace.SpanProcessor
+	if disableBatch {
+		processor = trace.NewSimpleSpanProcessor(exp)
+	} else {
+		processor = trace.NewBatchSpanProcessor(exp)
+	}
+
 	return trace.NewTracerProvider(
-		trace.WithBatcher(exp),
+		trace.WithSpanProcessor(processor),
 		trace.WithResource(r),
 	), nil
 }
 
 func (instance *Traceloop) initTracer(ctx context.Context, serviceName string) error {
-	exp, err := newTraceloopExporter(ctx, instance.config)
-	if err != nil {
-		return fmt.Errorf("create otlp exporter: %w", err)
+	var exp trace.SpanExporter = instance.config.Exporter
+	if exp == nil {
+		otlpExp, err := newTraceloopExporter(ctx, instance.config)
+		if err != nil {
+			return fmt.Errorf("create otlp exporter: %w", err)
+		}
+		exp = otlpExp
 	}
 
-	tp, err := newTracerProvider(ctx, serviceName, exp)
+	tp, err := newTracerProvider(ctx, serviceName, exp, instance.config.DisableBatch)
 	if err != nil {
 		return fmt.Errorf("create tracer provider: %w", err)
 	}
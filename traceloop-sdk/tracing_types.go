@@ -1,39 +1,103 @@
 package traceloop
 
 type Message struct {
-	Index     int        `json:"index"`
-	Role      string     `json:"role"`
-	Content   string     `json:"content"`
-	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	Index        int           `json:"index"`
+	Role         string        `json:"role"`
+	Content      string        `json:"content"`
+	ContentParts []ContentPart `json:"content_parts,omitempty"`
+	ToolCalls    []ToolCall    `json:"tool_calls,omitempty"`
+	ToolCallID   string        `json:"tool_call_id,omitempty"`
+	FinishReason string        `json:"finish_reason,omitempty"`
+}
+
+type ContentPart struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	URL      string `json:"url,omitempty"`
+	Data     []byte `json:"data,omitempty"`
+	MimeType string `json:"mime_type,omitempty"`
+	FileID   string `json:"file_id,omitempty"`
+	Filename string `json:"filename,omitempty"`
 }
 
 type Prompt struct {
-	Vendor           string    `json:"vendor"`
-	Model            string    `json:"model"`
-	Mode             string    `json:"mode"`
-	Temperature      float32   `json:"temperature"`
-	TopP             float32   `json:"top_p"`
-	Stop             []string  `json:"stop"`
-	FrequencyPenalty float32   `json:"frequency_penalty"`
-	PresencePenalty  float32   `json:"presence_penalty"`
-	Messages         []Message `json:"messages"`
-	Tools            []Tool    `json:"tools,omitempty"`
+	Vendor string `json:"vendor"`
+	Model  string `json:"model"`
+	Mode   string `json:"mode"`
+	// Temperature, TopP, the penalties and Seed are nil when the request
+	// leaves them to the provider, as 0 is a valid value for each of them.
+	Temperature      *float32       `json:"temperature,omitempty"`
+	TopP             *float32       `json:"top_p,omitempty"`
+	Stop             []string       `json:"stop"`
+	FrequencyPenalty *float32       `json:"frequency_penalty,omitempty"`
+	PresencePenalty  *float32       `json:"presence_penalty,omitempty"`
+	MaxTokens        int            `json:"max_tokens,omitempty"`
+	Seed             *int           `json:"seed,omitempty"`
+	N                int            `json:"n,omitempty"`
+	ResponseFormat   string         `json:"response_format,omitempty"`
+	ToolChoice       string         `json:"tool_choice,omitempty"`
+	User             string         `json:"user,omitempty"`
+	LogitBias        map[string]int `json:"logit_bias,omitempty"`
+	Messages         []Message      `json:"messages"`
+	Tools            []Tool         `json:"tools,omitempty"`
+}
+
+// Ptr returns a pointer to value, to set the optional parameters of a Prompt.
+func Ptr[T any](value T) *T {
+	return &value
 }
 
 type Completion struct {
+	// ID is the identifier the vendor gave to the response, if any.
+	ID       string    `json:"id,omitempty"`
 	Model    string    `json:"model"`
 	Messages []Message `json:"messages"`
 }
 
+type EmbeddingPrompt struct {
+	Vendor         string   `json:"vendor"`
+	Model          string   `json:"model"`
+	Inputs         []string `json:"inputs,omitempty"`
+	InputCount     int      `json:"input_count,omitempty"`
+	Dimensions     int      `json:"dimensions,omitempty"`
+	EncodingFormat string   `json:"encoding_format,omitempty"`
+	User           string   `json:"user,omitempty"`
+}
+
+type Embeddings struct {
+	Model      string `json:"model"`
+	Count      int    `json:"count"`
+	Dimensions int    `json:"dimensions"`
+}
+
 type WorkflowAttributes struct {
 	Name                  string            `json:"workflow_name"`
 	AssociationProperties map[string]string `json:"association_properties"`
 }
 
+type AgentAttributes struct {
+	Name          string `json:"agent_name"`
+	MaxIterations int    `json:"max_iterations,omitempty"`
+}
+
 type Usage struct {
-	TotalTokens      int `json:"total_tokens"`
-	CompletionTokens int `json:"completion_tokens"`
-	PromptTokens     int `json:"prompt_tokens"`
+	TotalTokens              int `json:"total_tokens"`
+	CompletionTokens         int `json:"completion_tokens"`
+	PromptTokens             int `json:"prompt_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens,omitempty"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens,omitempty"`
+	// ReasoningTokens are spent by reasoning models thinking before they
+	// answer. Like the audio and image tokens, they are a breakdown of the
+	// prompt or completion tokens, which include them.
+	ReasoningTokens       int `json:"reasoning_tokens,omitempty"`
+	PromptAudioTokens     int `json:"prompt_audio_tokens,omitempty"`
+	CompletionAudioTokens int `json:"completion_audio_tokens,omitempty"`
+	PromptImageTokens     int `json:"prompt_image_tokens,omitempty"`
+	CompletionImageTokens int `json:"completion_image_tokens,omitempty"`
+	// ImageCount and AudioSeconds are billed by models priced per image
+	// generated or per second of audio transcribed.
+	ImageCount   int     `json:"image_count,omitempty"`
+	AudioSeconds float64 `json:"audio_seconds,omitempty"`
 }
 
 type ToolFunction struct {
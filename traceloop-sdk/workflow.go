@@ -20,7 +20,10 @@ type Task struct {
 	Name     string `json:"name"`
 }
 
+// NewWorkflow starts a workflow. Its association properties are added to
+// those carried by ctx, and recorded on every span started under it.
 func (instance *Traceloop) NewWorkflow(ctx context.Context, attrs WorkflowAttributes) *Workflow {
+	ctx = WithAssociationProperties(ctx, attrs.AssociationProperties)
 	wCtx, span := instance.getTracer().Start(ctx, fmt.Sprintf("%s.workflow", attrs.Name), trace.WithNewRoot())
 
 	span.SetAttributes(
@@ -28,12 +31,25 @@ func (instance *Traceloop) NewWorkflow(ctx context.Context, attrs WorkflowAttrib
 		semconvai.TraceloopSpanKind.String("workflow"),
 		semconvai.TraceloopEntityName.String(attrs.Name),
 	)
+	span.SetAttributes(associationPropertiesAttributes(ctx, nil)...)
 
-	return &Workflow{
+	workflow := &Workflow{
 		sdk:        instance,
-		ctx:        wCtx,
 		Attributes: attrs,
 	}
+	// A workflow starts a new trace, so it leaves any task or entity path of
+	// ctx behind.
+	wCtx = context.WithValue(wCtx, entityPathKey{}, "")
+	wCtx = context.WithValue(wCtx, taskKey{}, (*Task)(nil))
+	workflow.ctx = context.WithValue(wCtx, workflowKey{}, workflow)
+
+	return workflow
+}
+
+// Context returns the context carrying the workflow span, so calls made with
+// it (e.g. through an instrumented LLM client) are recorded under the workflow.
+func (workflow *Workflow) Context() context.Context {
+	return workflow.ctx
 }
 
 func (workflow *Workflow) End() {
@@ -45,19 +61,29 @@ func (workflow *Workflow) LogPrompt(prompt Prompt) (LLMSpan, error) {
 }
 
 func (workflow *Workflow) NewTask(name string) *Task {
-	tCtx, span := workflow.sdk.getTracer().Start(workflow.ctx, fmt.Sprintf("%s.task", name))
+	return workflow.newTask(workflow.ctx, name)
+}
 
-	span.SetAttributes(
-		semconvai.TraceloopWorkflowName.String(workflow.Attributes.Name),
-		semconvai.TraceloopSpanKind.String("task"),
-		semconvai.TraceloopEntityName.String(name),
-	)
+func (workflow *Workflow) newTask(ctx context.Context, name string) *Task {
+	tCtx, _ := workflow.sdk.startEntity(ctx, workflow.Attributes.Name, "task", name)
 
-	return &Task{
+	task := &Task{
 		workflow: workflow,
-		ctx:      tCtx,
 		Name:     name,
 	}
+	task.ctx = context.WithValue(tCtx, taskKey{}, task)
+
+	return task
+}
+
+// NewTask starts a task nested under this one.
+func (task *Task) NewTask(name string) *Task {
+	return task.workflow.newTask(task.ctx, name)
+}
+
+// Context returns the context carrying the task span.
+func (task *Task) Context() context.Context {
+	return task.ctx
 }
 
 func (task *Task) End() {
@@ -67,3 +93,55 @@ func (task *Task) End() {
 func (task *Task) LogPrompt(prompt Prompt) (LLMSpan, error) {
 	return task.workflow.sdk.LogPrompt(task.ctx, prompt, task.workflow.Attributes)
 }
+
+type workflowKey struct{}
+
+type taskKey struct{}
+
+type entityPathKey struct{}
+
+// WorkflowFromContext returns the workflow carried by ctx, or nil. Contexts
+// returned by the Context method of a workflow, or of any task, agent or tool
+// started under it, carry the workflow.
+func WorkflowFromContext(ctx context.Context) *Workflow {
+	workflow, _ := ctx.Value(workflowKey{}).(*Workflow)
+	return workflow
+}
+
+// TaskFromContext returns the innermost task carried by ctx, or nil when ctx
+// carries no task, e.g. directly under a workflow.
+func TaskFromContext(ctx context.Context) *Task {
+	task, _ := ctx.Value(taskKey{}).(*Task)
+	return task
+}
+
+// entityPath returns the path of the task, agent or tool carried by ctx, made
+// of the names of the entities from the workflow down to it. It is empty
+// directly under a workflow.
+func entityPath(ctx context.Context) string {
+	path, _ := ctx.Value(entityPathKey{}).(string)
+	return path
+}
+
+// startEntity starts the span of a task, agent or tool, named after the entity
+// and its kind, and records the entity's path. The workflow name is omitted
+// for entities started outside of a workflow.
+func (instance *Traceloop) startEntity(ctx context.Context, workflowName, kind, name string) (context.Context, trace.Span) {
+	path := name
+	if parentPath := entityPath(ctx); parentPath != "" {
+		path = parentPath + "." + name
+	}
+
+	eCtx, span := instance.getTracer().Start(ctx, fmt.Sprintf("%s.%s", name, kind))
+	span.SetAttributes(
+		semconvai.TraceloopSpanKind.String(kind),
+		semconvai.TraceloopEntityName.String(name),
+		semconvai.TraceloopEntityPath.String(path),
+	)
+	if workflowName != "" {
+		span.SetAttributes(semconvai.TraceloopWorkflowName.String(workflowName))
+	}
+	span.SetAttributes(associationPropertiesAttributes(ctx, nil)...)
+
+	return context.WithValue(eCtx, entityPathKey{}, path), span
+}
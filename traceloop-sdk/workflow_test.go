@@ -0,0 +1,115 @@
+package traceloop
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestNestedTasks(t *testing.T) {
+	tl, exporter := newTestTraceloop(t)
+	ctx := context.Background()
+
+	wf := tl.NewWorkflow(ctx, WorkflowAttributes{Name: "pipeline"})
+	ingest := wf.NewTask("ingest")
+	parse := ingest.NewTask("parse")
+	llmSpan, _ := parse.LogPrompt(Prompt{Vendor: "openai", Mode: "chat"})
+	llmSpan.LogCompletion(ctx, Completion{}, Usage{})
+	tool := parse.NewTool(ToolCall{Function: ToolCallFunction{Name: "lookup"}})
+	tool.LogResult(ctx, "found")
+	parse.End()
+	ingest.End()
+	wf.End()
+
+	spans := make(map[string]tracetest.SpanStub)
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+	if len(spans) != 5 {
+		t.Fatalf("Expected 5 spans, got %d", len(spans))
+	}
+
+	parents := map[string]string{
+		"ingest.task": "pipeline.workflow",
+		"parse.task":  "ingest.task",
+		"openai.chat": "parse.task",
+		"lookup.tool": "parse.task",
+	}
+	for child, parent := range parents {
+		if spans[child].Parent.SpanID() != spans[parent].SpanContext.SpanID() {
+			t.Errorf("Expected %s to be a child of %s", child, parent)
+		}
+	}
+
+	paths := map[string]string{
+		"ingest.task": "ingest",
+		"parse.task":  "ingest.parse",
+		"openai.chat": "ingest.parse",
+		"lookup.tool": "ingest.parse.lookup",
+	}
+	for name, path := range paths {
+		attrs := spanAttributeMap(spans[name])
+		if attrs["traceloop.entity.path"] != path {
+			t.Errorf("Span %s: expected entity path %s, got %v", name, path, attrs["traceloop.entity.path"])
+		}
+		if attrs["traceloop.workflow.name"] != "pipeline" {
+			t.Errorf("Span %s: expected workflow name pipeline, got %v", name, attrs["traceloop.workflow.name"])
+		}
+	}
+	if _, exists := spanAttributeMap(spans["pipeline.workflow"])["traceloop.entity.path"]; exists {
+		t.Errorf("Expected no entity path on the workflow span")
+	}
+}
+
+func TestWorkflowFromContext(t *testing.T) {
+	tl, exporter := newTestTraceloop(t)
+	ctx := context.Background()
+
+	if WorkflowFromContext(ctx) != nil || TaskFromContext(ctx) != nil {
+		t.Fatal("Expected no workflow or task in a background context")
+	}
+
+	wf := tl.NewWorkflow(ctx, WorkflowAttributes{
+		Name:                  "support",
+		AssociationProperties: map[string]string{"user_id": "user-1"},
+	})
+	task := wf.NewTask("answer")
+
+	if WorkflowFromContext(task.Context()) != wf {
+		t.Error("Expected the task context to carry the workflow")
+	}
+	if TaskFromContext(task.Context()) != task {
+		t.Error("Expected the task context to carry the task")
+	}
+	if TaskFromContext(wf.Context()) != nil {
+		t.Error("Expected the workflow context to carry no task")
+	}
+
+	// Deeper in the call stack, only the context is at hand.
+	llmSpan, _ := tl.LogPrompt(task.Context(), Prompt{Vendor: "openai", Mode: "chat"}, WorkflowAttributes{})
+	llmSpan.LogCompletion(ctx, Completion{}, Usage{})
+	task.End()
+
+	nested := tl.NewWorkflow(task.Context(), WorkflowAttributes{Name: "nested"})
+	if TaskFromContext(nested.Context()) != nil {
+		t.Error("Expected a new workflow to leave the task behind")
+	}
+	nested.End()
+	wf.End()
+
+	for _, span := range exporter.GetSpans() {
+		if span.Name != "openai.chat" {
+			continue
+		}
+		attrs := spanAttributeMap(span)
+		if attrs["traceloop.workflow.name"] != "support" {
+			t.Errorf("Expected the workflow name to be inferred, got %v", attrs["traceloop.workflow.name"])
+		}
+		if attrs["traceloop.association.properties.user_id"] != "user-1" {
+			t.Errorf("Expected the association properties to be inferred, got %v", attrs["traceloop.association.properties.user_id"])
+		}
+		return
+	}
+	t.Error("Expected an LLM span")
+}
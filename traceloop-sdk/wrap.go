@@ -0,0 +1,101 @@
+package traceloop
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+
+	semconvai "github.com/traceloop/go-openllmetry/semconv-ai"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	apitrace "go.opentelemetry.io/otel/trace"
+)
+
+// PanicError is returned by wrapped functions that panicked. The panic is
+// recovered so that the span is ended with the error recorded.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// WrapWorkflow returns fn traced as a workflow: every call starts a workflow
+// span carried by the context passed to fn, records the input and the output
+// as JSON, records the error returned, and ends the span.
+func WrapWorkflow[In, Out any](instance *Traceloop, attrs WorkflowAttributes, fn func(context.Context, In) (Out, error)) func(context.Context, In) (Out, error) {
+	return func(ctx context.Context, input In) (Out, error) {
+		workflow := instance.NewWorkflow(ctx, attrs)
+		return callTraced(workflow.ctx, input, fn)
+	}
+}
+
+// WrapTask returns fn traced as a task nested under the workflow, task or
+// agent carried by the context it is called with. Outside of a workflow, the
+// task span is recorded on its own, without a workflow name. Either way, fn
+// is called with a context carrying the task.
+func WrapTask[In, Out any](instance *Traceloop, name string, fn func(context.Context, In) (Out, error)) func(context.Context, In) (Out, error) {
+	return func(ctx context.Context, input In) (Out, error) {
+		workflow := WorkflowFromContext(ctx)
+		if workflow == nil {
+			workflow = &Workflow{sdk: instance}
+		}
+
+		return callTraced(workflow.newTask(ctx, name).Context(), input, fn)
+	}
+}
+
+// WrapTool returns fn traced as a tool, like WrapTask. The input is recorded
+// as the tool call's arguments.
+func WrapTool[In, Out any](instance *Traceloop, name string, fn func(context.Context, In) (Out, error)) func(context.Context, In) (Out, error) {
+	return func(ctx context.Context, input In) (Out, error) {
+		var workflowName string
+		if workflow := WorkflowFromContext(ctx); workflow != nil {
+			workflowName = workflow.Attributes.Name
+		}
+
+		tool := instance.newTool(ctx, workflowName, ToolCall{
+			Type:     "function",
+			Function: ToolCallFunction{Name: name},
+		})
+		return callTraced(tool.ctx, input, fn)
+	}
+}
+
+// callTraced calls fn with the context of an entity span, and ends the span
+// once fn returns or panics.
+func callTraced[In, Out any](ctx context.Context, input In, fn func(context.Context, In) (Out, error)) (output Out, err error) {
+	span := apitrace.SpanFromContext(ctx)
+	SetEntityAttribute(span, semconvai.TraceloopEntityInput, input)
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		} else {
+			SetEntityAttribute(span, semconvai.TraceloopEntityOutput, output)
+		}
+		span.End()
+	}()
+
+	return fn(ctx, input)
+}
+
+// SetEntityAttribute records value as JSON on the span of a workflow, task or
+// tool, typically under TraceloopEntityInput or TraceloopEntityOutput.
+func SetEntityAttribute(span apitrace.Span, key attribute.Key, value any) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		fmt.Printf("Failed to marshal %s: %v\n", key, err)
+		return
+	}
+
+	span.SetAttributes(key.String(string(data)))
+}
@@ -0,0 +1,128 @@
+package traceloop
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type forecastRequest struct {
+	City string `json:"city"`
+}
+
+func TestWrap(t *testing.T) {
+	tl, exporter := newTestTraceloop(t)
+
+	getWeather := WrapTool(tl, "get_weather", func(ctx context.Context, city string) (string, error) {
+		return "sunny", nil
+	})
+	forecast := WrapTask(tl, "forecast", func(ctx context.Context, req forecastRequest) (string, error) {
+		return getWeather(ctx, req.City)
+	})
+	answer := WrapWorkflow(tl, WorkflowAttributes{Name: "weather"}, func(ctx context.Context, city string) (string, error) {
+		if WorkflowFromContext(ctx) == nil {
+			t.Error("Expected the workflow to be carried by the context")
+		}
+		return forecast(ctx, forecastRequest{City: city})
+	})
+
+	output, err := answer(context.Background(), "Paris")
+	if err != nil || output != "sunny" {
+		t.Fatalf("Expected the wrapped functions to return sunny, got %q, %v", output, err)
+	}
+
+	spans := make(map[string]tracetest.SpanStub)
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+	if len(spans) != 3 {
+		t.Fatalf("Expected 3 spans, got %d", len(spans))
+	}
+	if spans["forecast.task"].Parent.SpanID() != spans["weather.workflow"].SpanContext.SpanID() {
+		t.Error("Expected the task span to be a child of the workflow span")
+	}
+	if spans["get_weather.tool"].Parent.SpanID() != spans["forecast.task"].SpanContext.SpanID() {
+		t.Error("Expected the tool span to be a child of the task span")
+	}
+
+	expected := map[string]map[string]interface{}{
+		"weather.workflow": {
+			"traceloop.entity.input":  `"Paris"`,
+			"traceloop.entity.output": `"sunny"`,
+		},
+		"forecast.task": {
+			"traceloop.workflow.name": "weather",
+			"traceloop.entity.input":  `{"city":"Paris"}`,
+			"traceloop.entity.output": `"sunny"`,
+		},
+		"get_weather.tool": {
+			"traceloop.span.kind":     "tool",
+			"traceloop.entity.path":   "forecast.get_weather",
+			"traceloop.entity.input":  `"Paris"`,
+			"traceloop.entity.output": `"sunny"`,
+		},
+	}
+	for name, expectedAttrs := range expected {
+		attrs := spanAttributeMap(spans[name])
+		for key, value := range expectedAttrs {
+			if attrs[key] != value {
+				t.Errorf("Span %s attribute %s: expected %v, got %v", name, key, value, attrs[key])
+			}
+		}
+	}
+}
+
+func TestWrapError(t *testing.T) {
+	tl, exporter := newTestTraceloop(t)
+
+	failing := WrapTask(tl, "failing", func(ctx context.Context, input int) (int, error) {
+		if task := TaskFromContext(ctx); task == nil || task.Name != "failing" {
+			t.Errorf("Expected the task to be carried by the context, got %v", task)
+		}
+		return 0, errors.New("division by zero")
+	})
+	if _, err := failing(context.Background(), 1); err == nil {
+		t.Fatal("Expected the error to be returned")
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	if status := spans[0].Status; status.Code != codes.Error || status.Description != "division by zero" {
+		t.Errorf("Expected the span status to be an error, got %v", status)
+	}
+	attrs := spanAttributeMap(spans[0])
+	if _, exists := attrs["traceloop.entity.output"]; exists {
+		t.Error("Expected no output to be recorded")
+	}
+	if _, exists := attrs["traceloop.workflow.name"]; exists {
+		t.Error("Expected no workflow name outside of a workflow")
+	}
+}
+
+func TestWrapPanic(t *testing.T) {
+	tl, exporter := newTestTraceloop(t)
+
+	panicking := WrapTask(tl, "panicking", func(ctx context.Context, input []int) (int, error) {
+		return input[3], nil
+	})
+
+	_, err := panicking(context.Background(), nil)
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("Expected the panic to be returned as a PanicError, got %v", err)
+	}
+	if !strings.Contains(panicErr.Error(), "index out of range") {
+		t.Errorf("Expected the panic value in the error, got %q", panicErr.Error())
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Status.Code != codes.Error {
+		t.Fatalf("Expected the span to be ended with an error, got %v", spans)
+	}
+}